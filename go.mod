@@ -28,6 +28,7 @@ require (
 	github.com/tektoncd/triggers v0.8.1
 	github.com/zalando/go-keyring v0.1.0
 	gopkg.in/AlecAivazis/survey.v1 v1.8.0
+	gopkg.in/yaml.v2 v2.3.0
 	k8s.io/api v0.18.2
 	k8s.io/apimachinery v0.18.2
 	k8s.io/client-go v12.0.0+incompatible
@@ -25,6 +25,7 @@ import (
 	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
 	"github.com/redhat-developer/kam/pkg/pipelines/imagerepo"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
 	"github.com/redhat-developer/kam/pkg/pipelines/statustracker"
 )
@@ -245,12 +246,11 @@ func initiateInteractiveMode(io *BootstrapParameters, client *utility.Client, cm
 }
 
 func repoFromURL(raw string) (string, error) {
-	u, err := url.Parse(raw)
+	_, _, repo, err := scm.ParseRepo(raw)
 	if err != nil {
 		return "", err
 	}
-	parts := strings.Split(u.Path, "/")
-	return strings.TrimSuffix(parts[len(parts)-1], ".git"), nil
+	return repo, nil
 }
 
 func setAccessToken(io *BootstrapParameters) error {
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/openshift/odo/pkg/log"
 	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
@@ -21,6 +22,12 @@ var (
 	buildExample = ktemplates.Examples(`
 	# Build files from pipelines
 	%[1]s 
+
+	# Build files from pipelines, failing if the manifest has unknown fields
+	%[1]s --strict
+
+	# List the files that would be built from pipelines
+	%[1]s --list
 	`)
 
 	buildLongDesc  = ktemplates.LongDesc(`Build GitOps pipelines files, generating the ArgoCD applications and OpenShift Pipelines EventListener`)
@@ -31,6 +38,8 @@ var (
 type BuildParameters struct {
 	pipelinesFolderPath string
 	output              string // path to add Gitops resources
+	strict              bool   // reject unknown fields in the manifest
+	list                bool   // list the paths of the resources instead of writing them
 }
 
 // NewBuildParameters bootstraps a BuildParameters instance.
@@ -53,6 +62,17 @@ func (io *BuildParameters) Run() error {
 	options := pipelines.BuildParameters{
 		PipelinesFolderPath: io.pipelinesFolderPath,
 		OutputPath:          io.output,
+		Strict:              io.strict,
+	}
+	if io.list {
+		paths, err := pipelines.ListResources(&options, ioutils.NewFilesystem())
+		if err != nil {
+			return err
+		}
+		for _, p := range paths {
+			fmt.Println(filepath.Join(io.output, p))
+		}
+		return nil
 	}
 	err := pipelines.BuildResources(&options, ioutils.NewFilesystem())
 	if err != nil {
@@ -77,5 +97,7 @@ func NewCmdBuild(name, fullName string) *cobra.Command {
 
 	buildCmd.Flags().StringVar(&o.output, "output", ".", "Folder path to add GitOps resources")
 	buildCmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	buildCmd.Flags().BoolVar(&o.list, "list", false, "List the paths of the files that would be built, without writing them")
+	buildCmd.Flags().BoolVar(&o.strict, "strict", false, "Fail if the manifest contains unknown fields")
 	return buildCmd
 }
@@ -64,12 +64,12 @@ const (
 	ArgoCDNamespace = "openshift-gitops"
 
 	defaultServer  = "https://kubernetes.default.svc"
-	defaultProject = "default"
+	defaultProject = config.DefaultArgoCDProject
 )
 
 // Build creates and returns a set of resources to be used for the ArgoCD
-// configuration.
-func Build(argoNS, repoURL string, m *config.Manifest) (res.Resources, error) {
+// configuration, the applications are named by names.
+func Build(argoNS, repoURL string, m *config.Manifest, names config.Names) (res.Resources, error) {
 	if repoURL == "" {
 		return res.Resources{}, nil
 	}
@@ -80,7 +80,7 @@ func Build(argoNS, repoURL string, m *config.Manifest) (res.Resources, error) {
 	}
 
 	files := make(res.Resources)
-	eb := &argocdBuilder{repoURL: repoURL, files: files, argoCDConfig: argoCDConfig, argoNS: argoNS}
+	eb := &argocdBuilder{repoURL: repoURL, files: files, argoCDConfig: argoCDConfig, argoNS: argoNS, manifest: m, names: names}
 	err := m.Walk(eb)
 	if err != nil {
 		return nil, err
@@ -97,16 +97,18 @@ type argocdBuilder struct {
 	argoCDConfig *config.ArgoCDConfig
 	files        res.Resources
 	argoNS       string
+	manifest     *config.Manifest
+	names        config.Names
 }
 
 func (b *argocdBuilder) Application(env *config.Environment, app *config.Application) error {
-	basePath := filepath.Join(config.PathForArgoCD())
 	argoFiles := res.Resources{}
-	filename := filepath.Join(basePath, env.Name+"-"+app.Name+"-app.yaml")
+	appName := b.names.ArgoCDApplicationName(env.Name, app.Name)
+	filename := config.PathForArgoCDEnvironmentApplication(appName)
 
-	argoFiles[filename] = makeApplication(app, env.Name+"-"+app.Name, b.argoNS,
-		defaultProject,
-		env.Name,
+	argoFiles[filename] = makeApplication(app, appName, b.argoNS,
+		b.manifest.ArgoCDProject(env),
+		config.EnvironmentNamespace(env),
 		clusterForEnv(env),
 		makeAppSource(env, app, b.repoURL))
 	b.files = res.Merge(argoFiles, b.files)
@@ -114,15 +116,15 @@ func (b *argocdBuilder) Application(env *config.Environment, app *config.Applica
 }
 
 func (b *argocdBuilder) Environment(env *config.Environment) error {
-	basePath := filepath.Join(config.PathForArgoCD())
 	argoFiles := res.Resources{}
-	filename := filepath.Join(basePath, env.Name+"-env-app.yaml")
+	appName := b.names.ArgoCDEnvironmentName(env.Name)
+	filename := config.PathForArgoCDEnvironmentApplication(appName)
 
 	argoFiles[filename] = makeApplication(
 		nil,
-		env.Name+"-env", b.argoNS,
-		defaultProject,
-		env.Name,
+		appName, b.argoNS,
+		b.manifest.ArgoCDProject(env),
+		config.EnvironmentNamespace(env),
 		clusterForEnv(env),
 		makeEnvSource(env, b.repoURL))
 	b.files = res.Merge(argoFiles, b.files)
@@ -134,14 +136,14 @@ func argoCDConfigResources(cfg *config.Config, repoURL string, files res.Resourc
 		return nil
 	}
 	basePath := filepath.Join(config.PathForArgoCD())
-	filename := filepath.Join(basePath, "kustomization.yaml")
-	files[filepath.Join(basePath, "argo-app.yaml")] =
-		ignoreDifferences(makeApplication(nil, "argo-app", cfg.ArgoCD.Namespace,
+	filename := filepath.Join(basePath, config.KustomizationFile)
+	files[config.PathForArgoCDApplication(config.ArgoCDConfigAppName)] =
+		ignoreDifferences(makeApplication(nil, config.ArgoCDConfigAppName, cfg.ArgoCD.Namespace,
 			defaultProject, cfg.ArgoCD.Namespace, defaultServer,
 			&argoappv1.ApplicationSource{RepoURL: repoURL, Path: basePath}))
 	if cfg.Pipelines != nil {
-		files[filepath.Join(basePath, "cicd-app.yaml")] = ignoreDifferences(
-			makeApplication(nil, "cicd-app", cfg.ArgoCD.Namespace, defaultProject, cfg.Pipelines.Name, defaultServer,
+		files[config.PathForArgoCDApplication(config.ArgoCDCICDAppName)] = ignoreDifferences(
+			makeApplication(nil, config.ArgoCDCICDAppName, cfg.ArgoCD.Namespace, defaultProject, cfg.Pipelines.Name, defaultServer,
 				&argoappv1.ApplicationSource{RepoURL: repoURL, Path: filepath.Join(config.PathForPipelines(cfg.Pipelines), "overlays")}))
 	}
 	resourceNames := []string{}
@@ -51,7 +51,7 @@ func TestBuildCreatesArgoCD(t *testing.T) {
 		},
 	}
 
-	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	files, err := Build(ArgoCDNamespace, testRepoURL, m, config.Names{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -128,7 +128,7 @@ func TestBuildCreatesArgoCDWithMultipleApps(t *testing.T) {
 		},
 	}
 
-	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	files, err := Build(ArgoCDNamespace, testRepoURL, m, config.Names{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -159,7 +159,7 @@ func TestBuildWithNoRepoURL(t *testing.T) {
 		},
 	}
 
-	files, err := Build(ArgoCDNamespace, "", m)
+	files, err := Build(ArgoCDNamespace, "", m, config.Names{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -176,7 +176,7 @@ func TestBuildWithNoArgoCDConfig(t *testing.T) {
 		},
 	}
 
-	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	files, err := Build(ArgoCDNamespace, testRepoURL, m, config.Names{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -203,7 +203,7 @@ func TestBuildWithRepoConfig(t *testing.T) {
 		},
 	}
 
-	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	files, err := Build(ArgoCDNamespace, testRepoURL, m, config.Names{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -273,7 +273,7 @@ func TestBuildAddsClusterToApp(t *testing.T) {
 		},
 	}
 
-	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	files, err := Build(ArgoCDNamespace, testRepoURL, m, config.Names{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -327,6 +327,40 @@ func TestBuildAddsClusterToApp(t *testing.T) {
 	}
 }
 
+func TestBuildAssignsArgoCDProjects(t *testing.T) {
+	m := &config.Manifest{
+		Environments: []*config.Environment{
+			{Name: "test-dev", Apps: []*config.Application{testApp}},
+			{Name: "test-stage", ArgoCDProject: "staging", Apps: []*config.Application{testApp}},
+		},
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: ArgoCDNamespace, Project: "team-a"},
+		},
+	}
+
+	files, err := Build(ArgoCDNamespace, testRepoURL, m, config.Names{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"config/argocd/argo-app.yaml":                defaultProject,
+		"config/argocd/test-dev-env-app.yaml":        "team-a",
+		"config/argocd/test-dev-http-api-app.yaml":   "team-a",
+		"config/argocd/test-stage-env-app.yaml":      "staging",
+		"config/argocd/test-stage-http-api-app.yaml": "staging",
+	}
+	got := map[string]string{}
+	for filename, r := range files {
+		if app, ok := r.(*argoappv1.Application); ok {
+			got[filename] = app.Spec.Project
+		}
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("projects didn't match: %s\n", diff)
+	}
+}
+
 func TestIgnoreDifferences(t *testing.T) {
 	want := &argoappv1.Application{
 		TypeMeta:   applicationTypeMeta,
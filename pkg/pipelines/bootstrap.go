@@ -3,11 +3,9 @@ package pipelines
 import (
 	"errors"
 	"fmt"
-	"net/url"
 	"path/filepath"
 	"sort"
 	"strconv"
-	"strings"
 
 	ssv1alpha1 "github.com/bitnami-labs/sealed-secrets/pkg/apis/sealed-secrets/v1alpha1"
 	"github.com/mitchellh/go-homedir"
@@ -41,7 +39,7 @@ import (
 
 const (
 	// Kustomize constants for kustomization.yaml
-	Kustomize = "kustomization.yaml"
+	Kustomize = config.KustomizationFile
 
 	namespacesPath        = "01-namespaces/cicd-environment.yaml"
 	rolesPath             = "02-rolebindings/pipeline-service-role.yaml"
@@ -56,7 +54,7 @@ const (
 	appCiPipelinesPath    = "05-pipelines/app-ci-pipeline.yaml"
 	pushTemplatePath      = "07-templates/ci-dryrun-from-push-template.yaml"
 	appCIPushTemplatePath = "07-templates/app-ci-build-from-push-template.yaml"
-	eventListenerPath     = "08-eventlisteners/cicd-event-listener.yaml"
+	eventListenerPath     = config.EventListenerPath
 	routePath             = "09-routes/gitops-webhook-event-listener.yaml"
 
 	dockerSecretName = "regcred"
@@ -143,7 +141,7 @@ func Bootstrap(o *BootstrapOptions, appFs afero.Fs) error {
 	}
 
 	m := bootstrapped[pipelinesFile].(*config.Manifest)
-	built, err := buildResources(appFs, m)
+	built, err := buildResources(appFs, m, config.Names{})
 	if err != nil {
 		return fmt.Errorf("failed to build resources: %v", err)
 	}
@@ -378,22 +376,19 @@ func applicationFromRepo(repoURL string, service *config.Service) (*config.Appli
 }
 
 func repoFromURL(raw string) (string, error) {
-	u, err := url.Parse(raw)
+	_, _, repo, err := scm.ParseRepo(raw)
 	if err != nil {
 		return "", err
 	}
-	parts := strings.Split(u.Path, "/")
-	return strings.TrimSuffix(parts[len(parts)-1], ".git"), nil
+	return repo, nil
 }
 
 func orgRepoFromURL(raw string) (string, error) {
-	u, err := url.Parse(raw)
+	_, owner, repo, err := scm.ParseRepo(raw)
 	if err != nil {
 		return "", err
 	}
-	parts := strings.Split(u.Path, "/")
-	orgRepo := strings.Join(parts[len(parts)-2:], "/")
-	return strings.TrimSuffix(orgRepo, ".git"), nil
+	return owner + "/" + repo, nil
 }
 
 func createBootstrapService(appName, ns, name string) *corev1.Service {
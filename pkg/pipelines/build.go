@@ -14,15 +14,17 @@ import (
 type BuildParameters struct {
 	PipelinesFolderPath string
 	OutputPath          string
+	Strict              bool                 // reject unknown fields in the manifest
+	NameSanitizer       config.NameSanitizer // derives the names of the resources, the default is config.HashSanitizer
 }
 
 // BuildResources builds all resources from a pipelines.
 func BuildResources(o *BuildParameters, appFs afero.Fs) error {
-	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	m, err := loadBuildManifest(o, appFs)
 	if err != nil {
 		return err
 	}
-	resources, err := buildResources(appFs, m)
+	resources, err := buildResources(appFs, m, config.Names{Sanitizer: o.NameSanitizer})
 	if err != nil {
 		return err
 	}
@@ -30,7 +32,24 @@ func BuildResources(o *BuildParameters, appFs afero.Fs) error {
 	return err
 }
 
-func buildResources(fs afero.Fs, m *config.Manifest) (res.Resources, error) {
+// ListResources returns the paths of the files that BuildResources would write,
+// relative to the output path.
+func ListResources(o *BuildParameters, appFs afero.Fs) ([]string, error) {
+	m, err := loadBuildManifest(o, appFs)
+	if err != nil {
+		return nil, err
+	}
+	return config.PathContext{Names: config.Names{Sanitizer: o.NameSanitizer}}.ResourcePaths(m)
+}
+
+func loadBuildManifest(o *BuildParameters, appFs afero.Fs) (*config.Manifest, error) {
+	if o.Strict {
+		return config.LoadManifestStrict(appFs, o.PipelinesFolderPath)
+	}
+	return config.LoadManifest(appFs, o.PipelinesFolderPath)
+}
+
+func buildResources(fs afero.Fs, m *config.Manifest, names config.Names) (res.Resources, error) {
 	resources := res.Resources{}
 
 	argoCD := m.GetArgoCDConfig()
@@ -45,13 +64,13 @@ func buildResources(fs afero.Fs, m *config.Manifest) (res.Resources, error) {
 	}
 	resources = res.Merge(envs, resources)
 
-	elFiles, err := buildEventListenerResources(m.GitOpsURL, m)
+	elFiles, err := buildEventListenerResources(m.GitOpsURL, m, names)
 	if err != nil {
 		return nil, err
 	}
 
 	resources = res.Merge(elFiles, resources)
-	argoApps, err := argocd.Build(argocd.ArgoCDNamespace, m.GitOpsURL, m)
+	argoApps, err := argocd.Build(argocd.ArgoCDNamespace, m.GitOpsURL, m, names)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,40 @@
+package pipelines
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+// prefixSanitizer joins the parts of names with a prefix.
+type prefixSanitizer struct{}
+
+func (prefixSanitizer) Sanitize(limit int, parts ...string) string {
+	return "kam-" + strings.Join(parts, "-")
+}
+
+func TestResourcePathsMatchBuild(t *testing.T) {
+	for _, names := range []config.Names{{}, {Sanitizer: prefixSanitizer{}}} {
+		fs := ioutils.NewMemoryFilesystem()
+		m, err := config.ParseFile(ioutils.NewFilesystem(), "testdata/resource_paths.yaml")
+		assertNoError(t, err)
+
+		resources, err := buildResources(fs, m, names)
+		assertNoError(t, err)
+		built := []string{}
+		for k := range resources {
+			built = append(built, k)
+		}
+		sort.Strings(built)
+
+		paths, err := config.PathContext{Names: names}.ResourcePaths(m)
+		assertNoError(t, err)
+		if diff := cmp.Diff(built, paths); diff != "" {
+			t.Fatalf("ResourcePaths() did not match the built resources:\n%s", diff)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+)
+
+// The names of the Argo CD applications that are generated from the config.
+const (
+	// ArgoCDConfigAppName is the name of the application that syncs the Argo
+	// CD configuration.
+	ArgoCDConfigAppName = "argo-app"
+	// ArgoCDCICDAppName is the name of the application that syncs the CICD
+	// environment.
+	ArgoCDCICDAppName = "cicd-app"
+	// argoCDNameLimit is the longest Argo CD application name that is a valid
+	// Kubernetes name.
+	argoCDNameLimit = k8svalidation.DNS1123SubdomainMaxLength
+)
+
+// DefaultArgoCDProject is the Argo CD project that applications are assigned
+// to when neither the environment nor the Argo CD config has a project.
+const DefaultArgoCDProject = "default"
+
+// ArgoCDApplicationName returns the name of the Argo CD application that is
+// generated for the application in the environment.
+func ArgoCDApplicationName(env, app string) string {
+	return Names{}.ArgoCDApplicationName(env, app)
+}
+
+// ArgoCDApplicationName returns the name of the Argo CD application that is
+// generated for the application in the environment, derived with the
+// Sanitizer.
+func (n Names) ArgoCDApplicationName(env, app string) string {
+	return n.sanitize(argoCDNameLimit, env, app)
+}
+
+// ArgoCDEnvironmentName returns the name of the Argo CD application that is
+// generated for the environment.
+func ArgoCDEnvironmentName(env string) string {
+	return Names{}.ArgoCDEnvironmentName(env)
+}
+
+// ArgoCDEnvironmentName returns the name of the Argo CD application that is
+// generated for the environment, derived with the Sanitizer.
+func (n Names) ArgoCDEnvironmentName(env string) string {
+	return n.sanitize(argoCDNameLimit, env, "env")
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"fmt"
+
+	"knative.dev/pkg/apis"
+)
+
+// ServiceSpec describes a service to be added with a Builder.
+type ServiceSpec struct {
+	Name      string
+	SourceURL string
+	Webhook   *Webhook
+	Pipelines *Pipelines
+}
+
+// Builder constructs a Manifest, checking each environment, application and
+// service as it is added.
+//
+// The first error stops the build, later calls are ignored, and the error is
+// returned from Build.
+type Builder struct {
+	manifest *Manifest
+	vv       *validateVisitor
+	err      error
+}
+
+// NewBuilder creates and returns a Builder for an empty manifest.
+func NewBuilder() *Builder {
+	return &Builder{manifest: &Manifest{}, vv: newValidateVisitor()}
+}
+
+// WithGitOpsURL sets the GitOps URL of the manifest.
+func (b *Builder) WithGitOpsURL(gitOpsURL string) *Builder {
+	if b.err == nil {
+		b.manifest.GitOpsURL = gitOpsURL
+	}
+	return b
+}
+
+// WithConfig sets the config of the manifest, the names of the environments,
+// applications and services added later are checked with its name policy.
+func (b *Builder) WithConfig(cfg *Config) *Builder {
+	if b.err != nil {
+		return b
+	}
+	b.manifest.Config = cfg
+	if errs := b.vv.validateConfig(b.manifest); len(errs) > 0 {
+		b.err = errs[0]
+	}
+	return b
+}
+
+// AddEnvironment adds an empty environment with the name.
+func (b *Builder) AddEnvironment(name string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	env := &Environment{Name: name}
+	path := yamlPath(PathForEnvironment(env))
+	if b.manifest.GetEnvironment(name) != nil {
+		b.err = duplicateFieldsError([]string{name}, []string{path})
+		return b
+	}
+	if err := b.vv.validateName(name, path); err != nil {
+		b.err = err
+		return b
+	}
+	b.manifest.Environments = append(b.manifest.Environments, env)
+	return b
+}
+
+// AddApplication adds an empty application to the named environment.
+func (b *Builder) AddApplication(envName, appName string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	env := b.manifest.GetEnvironment(envName)
+	if env == nil {
+		b.err = unknownEnvironmentError(envName, []string{yamlPath(PathForEnvironment(&Environment{Name: envName}))})
+		return b
+	}
+	app := &Application{Name: appName}
+	path := yamlPath(PathForApplication(env, app))
+	if b.manifest.GetApplication(envName, appName) != nil {
+		b.err = duplicateFieldsError([]string{appName}, []string{path})
+		return b
+	}
+	if err := b.vv.validateName(appName, path); err != nil {
+		b.err = err
+		return b
+	}
+	env.Apps = append(env.Apps, app)
+	return b
+}
+
+// AddService adds a service to the named application in the named
+// environment.
+func (b *Builder) AddService(appName, envName string, spec ServiceSpec) *Builder {
+	if b.err != nil {
+		return b
+	}
+	env := b.manifest.GetEnvironment(envName)
+	if env == nil {
+		b.err = unknownEnvironmentError(envName, []string{yamlPath(PathForEnvironment(&Environment{Name: envName}))})
+		return b
+	}
+	app := b.manifest.GetApplication(envName, appName)
+	if app == nil {
+		b.err = unknownApplicationError(appName, []string{yamlPath(PathForApplication(env, &Application{Name: appName}))})
+		return b
+	}
+	path := yamlPath(PathForService(app, env, spec.Name))
+	// service names are unique within an environment
+	for _, other := range env.Apps {
+		for _, svc := range other.Services {
+			if svc.Name == spec.Name {
+				b.err = duplicateFieldsError([]string{spec.Name}, []string{path})
+				return b
+			}
+		}
+	}
+	if err := b.vv.validateName(spec.Name, path); err != nil {
+		b.err = err
+		return b
+	}
+	app.Services = append(app.Services, &Service{
+		Name:      spec.Name,
+		SourceURL: spec.SourceURL,
+		Webhook:   spec.Webhook,
+		Pipelines: spec.Pipelines,
+	})
+	return b
+}
+
+// Build returns the manifest, or the first error from adding to it, or the
+// errors from validating it.
+func (b *Builder) Build() (*Manifest, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if err := b.manifest.Validate(); err != nil {
+		return nil, err
+	}
+	return b.manifest, nil
+}
+
+func unknownEnvironmentError(env string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("unknown environment %q", env),
+		Paths:   paths,
+	}
+}
+
+func unknownApplicationError(app string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("unknown application %q", app),
+		Paths:   paths,
+	}
+}
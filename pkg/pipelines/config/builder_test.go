@@ -0,0 +1,106 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mkmik/multierror"
+)
+
+func TestBuilder(t *testing.T) {
+	m, err := NewBuilder().
+		WithGitOpsURL("https://github.com/org/gitops.git").
+		AddEnvironment("development").
+		AddApplication("development", "my-app-1").
+		AddService("my-app-1", "development", ServiceSpec{Name: "service-http", SourceURL: "https://github.com/org/service-http.git"}).
+		AddEnvironment("staging").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Manifest{
+		GitOpsURL: "https://github.com/org/gitops.git",
+		Environments: []*Environment{
+			{
+				Name: "development",
+				Apps: []*Application{
+					{
+						Name: "my-app-1",
+						Services: []*Service{
+							{Name: "service-http", SourceURL: "https://github.com/org/service-http.git"},
+						},
+					},
+				},
+			},
+			{Name: "staging"},
+		},
+	}
+	if diff := cmp.Diff(want, m); diff != "" {
+		t.Fatalf("Build() failed:\n%s", diff)
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	builderTests := []struct {
+		desc    string
+		builder *Builder
+		wantErr error
+	}{
+		{
+			"duplicate environment",
+			NewBuilder().AddEnvironment("development").AddEnvironment("development"),
+			duplicateFieldsError([]string{"development"}, []string{"environments.development"}),
+		},
+		{
+			"invalid environment name",
+			NewBuilder().AddEnvironment("develo.pment"),
+			invalidNameError("develo.pment", DNS1035Error, []string{"environments.develo.pment"}),
+		},
+		{
+			"application in an unknown environment",
+			NewBuilder().AddApplication("development", "my-app-1"),
+			unknownEnvironmentError("development", []string{"environments.development"}),
+		},
+		{
+			"duplicate application",
+			NewBuilder().AddEnvironment("development").AddApplication("development", "my-app-1").AddApplication("development", "my-app-1"),
+			duplicateFieldsError([]string{"my-app-1"}, []string{"environments.development.apps.my-app-1"}),
+		},
+		{
+			"service in an unknown application",
+			NewBuilder().AddEnvironment("development").AddService("my-app-1", "development", ServiceSpec{Name: "service-http"}),
+			unknownApplicationError("my-app-1", []string{"environments.development.apps.my-app-1"}),
+		},
+		{
+			"duplicate service in an environment",
+			NewBuilder().AddEnvironment("development").
+				AddApplication("development", "my-app-1").
+				AddApplication("development", "my-app-2").
+				AddService("my-app-1", "development", ServiceSpec{Name: "service-http"}).
+				AddService("my-app-2", "development", ServiceSpec{Name: "service-http"}),
+			duplicateFieldsError([]string{"service-http"}, []string{"environments.development.apps.my-app-2.services.service-http"}),
+		},
+		{
+			"the first error is returned",
+			NewBuilder().AddApplication("development", "my-app-1").AddEnvironment("develo.pment"),
+			unknownEnvironmentError("development", []string{"environments.development"}),
+		},
+		{
+			"the manifest is validated",
+			NewBuilder().AddEnvironment("development").AddApplication("development", "my-app-1"),
+			multierror.Join([]error{
+				missingFieldsError([]string{"services", "config_repo"}, []string{"environments.development.apps.my-app-1"}),
+			}),
+		},
+	}
+
+	for _, tt := range builderTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			_, err := tt.builder.Build()
+			if err := matchMultiErrors(rt, err, tt.wantErr); err != nil {
+				rt.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"errors"
+
+	"knative.dev/pkg/apis"
+)
+
+// Category is the kind of problem reported by a validation error, so that
+// callers e.g. scripts, can handle them without matching the messages.
+type Category string
+
+const (
+	// CategoryNone is the category of a nil error.
+	CategoryNone Category = ""
+	// CategoryName is for invalid names, and names that don't follow the
+	// naming conventions.
+	CategoryName Category = "name"
+	// CategoryMissingField is for required fields that are missing.
+	CategoryMissingField Category = "missing-field"
+	// CategoryDuplicate is for names, source repositories and other values
+	// that must be unique, and are duplicated.
+	CategoryDuplicate Category = "duplicate"
+	// CategoryGitType is for services hosted by a different type of Git
+	// provider to the GitOps repository.
+	CategoryGitType Category = "git-type"
+	// CategoryOther is for the other validation errors.
+	CategoryOther Category = "other"
+)
+
+// categorizedError is a validation error with the category of the problem,
+// the category is set by the constructor of the error.
+type categorizedError struct {
+	*apis.FieldError
+	category Category
+}
+
+func (e *categorizedError) Unwrap() error {
+	return e.FieldError
+}
+
+func categorized(category Category, err *apis.FieldError) *categorizedError {
+	return &categorizedError{FieldError: err, category: category}
+}
+
+// ErrorCategory returns the category of a validation error, the errors in a
+// multi-error from Validate should be categorized separately, after splitting
+// them with multierror.Split.
+//
+// Errors that were not constructed with a category are CategoryOther.
+func ErrorCategory(err error) Category {
+	if err == nil {
+		return CategoryNone
+	}
+	var ce *categorizedError
+	if !errors.As(err, &ce) {
+		return CategoryOther
+	}
+	return ce.category
+}
+
+// asFieldError returns the field error of a validation error that is a field
+// error, or a categorized field error.
+func asFieldError(err error) (*apis.FieldError, bool) {
+	switch e := err.(type) {
+	case *apis.FieldError:
+		return e, true
+	case *categorizedError:
+		return e.FieldError, true
+	}
+	return nil, false
+}
+
+// withCategoryOf returns the field error with the category of the original
+// error, for errors that are rewritten e.g. with different paths.
+func withCategoryOf(original error, fe *apis.FieldError) error {
+	if ce, ok := original.(*categorizedError); ok {
+		return categorized(ce.category, fe)
+	}
+	return fe
+}
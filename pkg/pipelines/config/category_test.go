@@ -0,0 +1,40 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"knative.dev/pkg/apis"
+)
+
+func TestErrorCategory(t *testing.T) {
+	categoryTests := []struct {
+		err  error
+		want Category
+	}{
+		{nil, CategoryNone},
+		{invalidNameError("develo.pment", DNS1035Error, []string{"environments.develo.pment"}), CategoryName},
+		{whitespaceNameError("dev ", []string{"environments.dev "}), CategoryName},
+		{namePatternError("dev", &NamePattern{Prefix: "env-"}, []string{"environments.dev"}), CategoryName},
+		{invalidEnvironment("cicd", "Environment name cannot be the same as a config name.", []string{"environments.cicd"}), CategoryName},
+		{invalidGeneratedNameError("dev-app", "trigger", "too long", []string{"environments.dev"}), CategoryName},
+		{missingFieldsError([]string{"url"}, []string{"environments.dev.apps.app-1.config_repo"}), CategoryMissingField},
+		{emptyBlockError("webhook", []string{"secret"}, []string{"environments.dev.apps.app-1.services.svc.webhook"}), CategoryMissingField},
+		{missingGitOpsURLError([]string{"environments.dev.apps.app-1.services.svc"}), CategoryMissingField},
+		{duplicateFieldsError([]string{"dev"}, []string{"environments.dev"}), CategoryDuplicate},
+		{viaManifest(duplicateFieldsError([]string{"dev"}, []string{"environments.dev"}), 1), CategoryDuplicate},
+		{duplicateSourceError("https://github.com/org/repo", []string{"environments.dev.apps.app-1.services.svc"}), CategoryDuplicate},
+		{inconsistentGitTypeError("github", "https://gitlab.com/org/repo", []string{"environments.dev.apps.app-1.services.svc"}), CategoryGitType},
+		{apis.ErrMissingField("environments.dev.name"), CategoryOther},
+		{apis.ErrMultipleOneOf("environments.dev.apps.app-1.services", "environments.dev.apps.app-1.config_repo"), CategoryOther},
+		{&scm.UnknownDriverError{Host: "git.example.com", URL: "https://git.example.com/org/repo"}, CategoryOther},
+		{errors.New("failed"), CategoryOther},
+	}
+
+	for _, tt := range categoryTests {
+		if got := ErrorCategory(tt.err); got != tt.want {
+			t.Errorf("ErrorCategory(%v) got %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package config
+
+// Clone returns a deep copy of the manifest, changes to the copy, or to the
+// objects in it, do not affect the manifest.
+func (m *Manifest) Clone() *Manifest {
+	if m == nil {
+		return nil
+	}
+	copied := *m
+	copied.Config = copyConfig(m.Config)
+	copied.Environments = nil
+	if m.Environments != nil {
+		copied.Environments = make([]*Environment, len(m.Environments))
+		for i, env := range m.Environments {
+			copied.Environments[i] = copyEnvironment(env)
+		}
+	}
+	return &copied
+}
+
+func copyConfig(c *Config) *Config {
+	if c == nil {
+		return nil
+	}
+	copied := *c
+	if c.Pipelines != nil {
+		pipelines := *c.Pipelines
+		if c.Pipelines.Bindings != nil {
+			pipelines.Bindings = append([]string{}, c.Pipelines.Bindings...)
+		}
+		copied.Pipelines = &pipelines
+	}
+	if c.ArgoCD != nil {
+		argoCD := *c.ArgoCD
+		copied.ArgoCD = &argoCD
+	}
+	if c.Git != nil {
+		git := *c.Git
+		if c.Git.Drivers != nil {
+			git.Drivers = make(map[string]string, len(c.Git.Drivers))
+			for host, driver := range c.Git.Drivers {
+				git.Drivers[host] = driver
+			}
+		}
+		copied.Git = &git
+	}
+	if c.Defaults != nil {
+		copied.Defaults = &Defaults{Pipelines: copyPipelines(c.Defaults.Pipelines)}
+	}
+	return &copied
+}
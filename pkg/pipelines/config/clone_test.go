@@ -0,0 +1,56 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestClone(t *testing.T) {
+	m := cloneTestManifest(t)
+	original := cloneTestManifest(t)
+
+	clone := m.Clone()
+	if diff := cmp.Diff(m, clone); diff != "" {
+		t.Fatalf("Clone() did not match:\n%s", diff)
+	}
+
+	clone.GitOpsURL = "https://github.com/org/changed.git"
+	clone.Config.ArgoCD.Namespace = "changed"
+	clone.Config.Pipelines.Bindings[0] = "changed"
+	clone.Config.Git.Drivers["test.example.com"] = "gitlab"
+	clone.Config.Defaults.Pipelines.Integration.Bindings[0] = "changed"
+	clone.Environments[0].Name = "changed"
+	clone.Environments[0].Pipelines.Integration.Bindings[0] = "changed"
+	clone.Environments[0].Apps[0].Name = "changed"
+	svc := clone.Environments[0].Apps[0].Services[0]
+	svc.Name = "changed"
+	svc.Webhook.Secret.Name = "changed"
+	clone.Environments[1].Apps[0].ConfigRepo.Path = "changed"
+	clone.Environments[2].Apps = append(clone.Environments[2].Apps, &Application{Name: "added"})
+
+	if diff := cmp.Diff(original, m); diff != "" {
+		t.Fatalf("changing the clone modified the original manifest:\n%s", diff)
+	}
+}
+
+func TestCloneNil(t *testing.T) {
+	var m *Manifest
+	if clone := m.Clone(); clone != nil {
+		t.Fatalf("Clone() of a nil manifest returned %#v", clone)
+	}
+}
+
+// cloneTestManifest returns a manifest with every type of nested object set.
+func cloneTestManifest(t *testing.T) *Manifest {
+	t.Helper()
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/example1.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	m.Config.Pipelines.Bindings = []string{"dev-ci-binding"}
+	m.Config.Defaults = &Defaults{Pipelines: &Pipelines{Integration: &TemplateBinding{Template: "app-ci-template", Bindings: []string{"github-push-binding"}}}}
+	m.Environments[0].Apps[0].Services[0].Webhook = &Webhook{Secret: &Secret{Name: "webhook-secret", Namespace: "development"}}
+	return m
+}
@@ -1,14 +1,25 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"sort"
+
+	"github.com/mkmik/multierror"
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
 )
 
 const (
 	// PipelinesFile is the name of the pipelines manifest file
 	PipelinesFile = "pipelines.yaml"
+
+	// NamePolicyDNS1035 requires names to be DNS-1035 labels, this is the
+	// default.
+	NamePolicyDNS1035 = "dns1035"
+	// NamePolicyDNS1123 requires names to be DNS-1123 labels, which unlike
+	// DNS-1035 labels, can start with a digit.
+	NamePolicyDNS1123 = "dns1123"
 )
 
 // PathForService gives a repo-rooted path within a repository.
@@ -101,13 +112,41 @@ func (m *Manifest) GetArgoCDConfig() *ArgoCDConfig {
 	return nil
 }
 
+// ArgoCDProject returns the Argo CD project that the applications for the
+// environment are assigned to.
+func (m *Manifest) ArgoCDProject(env *Environment) string {
+	if env.ArgoCDProject != "" {
+		return env.ArgoCDProject
+	}
+	if argoCD := m.GetArgoCDConfig(); argoCD != nil && argoCD.Project != "" {
+		return argoCD.Project
+	}
+	return DefaultArgoCDProject
+}
+
+func (m *Manifest) skipGitTypeValidation() bool {
+	return m.Config != nil && m.Config.SkipGitTypeValidation
+}
+
 // Environment is a slice of Apps, these are the named apps in the namespace.
 //
 type Environment struct {
-	Name      string         `json:"name,omitempty"`
+	Name string `json:"name,omitempty"`
+	// Cluster is the URL of the API server that the environment is deployed
+	// to, if omitted, it is deployed to the cluster that Argo CD runs in.
 	Cluster   string         `json:"cluster,omitempty"`
 	Pipelines *Pipelines     `json:"pipelines,omitempty"`
 	Apps      []*Application `json:"apps,omitempty"`
+	// PromotesTo is the name of the environment that changes in this
+	// environment are promoted to e.g. development promotes to staging.
+	PromotesTo string `json:"promotes_to,omitempty"`
+	// Labels are arbitrary Kubernetes labels for the environment, e.g. for
+	// external automation.
+	Labels map[string]string `json:"labels,omitempty"`
+	// ArgoCDProject is the Argo CD project that the applications for the
+	// environment are assigned to, if omitted, the project from the Argo CD
+	// config is used.
+	ArgoCDProject string `json:"argocd_project,omitempty"`
 }
 
 // Config represents the configuration for non-application environments.
@@ -115,16 +154,41 @@ type Config struct {
 	Pipelines *PipelinesConfig `json:"pipelines,omitempty"`
 	ArgoCD    *ArgoCDConfig    `json:"argocd,omitempty"`
 	Git       *GitConfig       `json:"git,omitempty"`
+	// NamePolicy is the validation applied to names in the manifest, either
+	// NamePolicyDNS1035 or NamePolicyDNS1123.
+	NamePolicy string `json:"name_policy,omitempty"`
+	// Defaults are used for the parts of the environments that are not
+	// specified.
+	Defaults *Defaults `json:"defaults,omitempty"`
+	// SkipGitTypeValidation disables the check that services are hosted by
+	// the same type of Git provider as the GitOps repository, webhooks may
+	// not work for services hosted by a different type of provider.
+	SkipGitTypeValidation bool `json:"skip_git_type_validation,omitempty"`
+}
+
+// Defaults provides values for environments that don't specify their own.
+type Defaults struct {
+	// Pipelines are used for environments without pipelines, services
+	// without pipelines use the pipelines of their environment.
+	Pipelines *Pipelines `json:"pipelines,omitempty"`
 }
 
 // PipelinesConfig provides configuration for the CI/CD pipelines.
 type PipelinesConfig struct {
 	Name string `json:"name,omitempty"`
+	// Bindings are the names of the TriggerBindings available in the
+	// pipelines namespace, when provided, the bindings referenced by
+	// pipelines must be one of these.
+	Bindings []string `json:"bindings,omitempty"`
 }
 
 // ArgoCDConfig provides configuration for the ArgoCD application generation.
 type ArgoCDConfig struct {
 	Namespace string `json:"namespace,omitempty"`
+	// Project is the Argo CD project that the applications for the
+	// environments are assigned to, if they don't have their own project, if
+	// omitted, the DefaultArgoCDProject is used.
+	Project string `json:"project,omitempty"`
 }
 
 // GitConfig configures the git drivers.
@@ -152,17 +216,57 @@ type Service struct {
 	Webhook   *Webhook   `json:"webhook,omitempty"`
 	SourceURL string     `json:"source_url,omitempty"`
 	Pipelines *Pipelines `json:"pipelines,omitempty"`
+	// AllowForeignGitType allows the SourceURL to be hosted by a different
+	// type of Git provider to the GitOps repository.
+	AllowForeignGitType bool `json:"allow_foreign_git_type,omitempty"`
+	// AllowCrossNamespaceSecret allows the webhook secret to be in a
+	// namespace other than the environment or the pipelines namespace.
+	AllowCrossNamespaceSecret bool `json:"allow_cross_namespace_secret,omitempty"`
+	// SharedWebhookSecret allows the webhook secret to be used by other
+	// services.
+	SharedWebhookSecret bool `json:"shared_webhook_secret,omitempty"`
+	// ImageRepo is the repository that the image built for the service is
+	// pushed to, either <registry>/<namespace>/<name>, or <project>/<app> for
+	// the InternalImageRegistry.
+	ImageRepo string `json:"image_repo,omitempty"`
+	// Env are the environment variables for the deployment of the service.
+	Env []EnvVar `json:"env,omitempty"`
+}
+
+// EnvVar is an environment variable for the deployment of a service, with
+// either a Value, or a value read from a secret.
+type EnvVar struct {
+	Name  string `json:"name,omitempty"`
+	Value string `json:"value,omitempty"`
+	// ValueFrom is the secret that the value is read from, the Key is
+	// required.
+	ValueFrom *Secret `json:"value_from,omitempty"`
 }
 
 // Webhook provides Github webhook secret for eventlisteners
 type Webhook struct {
 	Secret *Secret `json:"secret,omitempty"`
+	// Provider is the type of Git provider that the webhook is configured
+	// for e.g. github, when provided, it should be the type of the Git
+	// provider that hosts the source_url of the service.
+	Provider string `json:"provider,omitempty"`
 }
 
 // Secret represents a K8s secret in a namespace
 type Secret struct {
 	Name      string `json:"name,omitempty"`
 	Namespace string `json:"namespace,omitempty"`
+	// Key is the key in the secret data that holds the webhook secret, if
+	// omitted, eventlisteners.WebhookSecretKey is used.
+	Key string `json:"key,omitempty"`
+}
+
+// SecretKey returns the key in the secret data that holds the webhook secret.
+func (s Secret) SecretKey() string {
+	if s.Key == "" {
+		return eventlisteners.WebhookSecretKey
+	}
+	return s.Key
 }
 
 // Repository refers to an upstream source for reading additional config from.
@@ -187,6 +291,10 @@ type Pipelines struct {
 type TemplateBinding struct {
 	Template string   `json:"template,omitempty"`
 	Bindings []string `json:"bindings,omitempty"`
+	// AllowEmptyBindings allows the integration of an environment to have no
+	// bindings, by default at least one binding is required, as the triggers
+	// would otherwise have no bindings.
+	AllowEmptyBindings bool `json:"allow_empty_bindings,omitempty"`
 }
 
 // Walk implements post-node visiting of each element in the manifest.
@@ -196,33 +304,119 @@ type TemplateBinding struct {
 //
 // The environments are sorted using a custom sorting mechanism, that orders by
 // name, but, moves CICD environments to the bottom of the list.
+//
+// Environments without pipelines are visited with the default pipelines from
+// the config, if there are any.
+//
+// If the manifest has more environments, applications or services than the
+// DefaultLimits, nothing is visited, and the error for the first limit that is
+// exceeded is returned.
 func (m Manifest) Walk(visitor interface{}) error {
+	return m.WalkContext(context.Background(), visitor)
+}
+
+// WalkContext visits the elements of the manifest in the same order as Walk,
+// the context is passed to the visitors that implement the context-aware
+// interfaces e.g. ServiceContextVisitor.
+//
+// The context is checked before each element is visited, if it is done, the
+// traversal stops and the context error is returned.
+func (m Manifest) WalkContext(ctx context.Context, visitor interface{}) error {
+	return m.walk(ctx, visitor, DefaultLimits, alwaysStop)
+}
+
+// WalkUntil visits the elements of the manifest in the same order as Walk, but
+// only stops the traversal when stop returns true for an error from one of the
+// handling functions, the other errors are collected.
+//
+// If a single error was returned by the handling functions it is returned
+// unchanged, otherwise the errors are returned as a multi-error.
+func (m Manifest) WalkUntil(visitor interface{}, stop func(error) bool) error {
+	return m.walk(context.Background(), visitor, DefaultLimits, stop)
+}
+
+func alwaysStop(error) bool {
+	return true
+}
+
+func neverStop(error) bool {
+	return false
+}
+
+func (m Manifest) walk(ctx context.Context, visitor interface{}, limits Limits, stop func(error) bool) error {
+	errs := []error{}
+	// failed records the error, and reports whether the traversal should
+	// stop.
+	failed := func(err error) bool {
+		if err == nil {
+			return false
+		}
+		errs = append(errs, err)
+		return stop(err)
+	}
+
+	defaults := m.defaultPipelines()
 	sort.Sort(byName(m.Environments))
+	if err := limits.check(&m); err != nil {
+		return err
+	}
+walk:
 	for _, env := range m.Environments {
+		env = withDefaultPipelines(env, defaults)
 		for _, app := range env.Apps {
 			for _, svc := range app.Services {
-				if v, ok := visitor.(ServiceVisitor); ok {
-					err := v.Service(app, env, svc)
-					if err != nil {
-						return err
-					}
+				if ctx.Err() != nil || failed(visitService(ctx, visitor, app, env, svc)) {
+					break walk
 				}
 			}
-			if v, ok := visitor.(ApplicationVisitor); ok {
-				err := v.Application(env, app)
-				if err != nil {
-					return err
-				}
+			if ctx.Err() != nil || failed(visitApplication(ctx, visitor, env, app)) {
+				break walk
 			}
 		}
-		if v, ok := visitor.(EnvironmentVisitor); ok {
-			err := v.Environment(env)
-			if err != nil {
-				return err
-			}
+		if ctx.Err() != nil || failed(visitEnvironment(ctx, visitor, env)) {
+			break walk
 		}
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return multierror.Join(errs)
+}
+
+func visitEnvironment(ctx context.Context, visitor interface{}, env *Environment) error {
+	switch v := visitor.(type) {
+	case EnvironmentContextVisitor:
+		return v.EnvironmentContext(ctx, env)
+	case EnvironmentVisitor:
+		return v.Environment(env)
+	}
+	return nil
+}
+
+func visitApplication(ctx context.Context, visitor interface{}, env *Environment, app *Application) error {
+	switch v := visitor.(type) {
+	case ApplicationContextVisitor:
+		return v.ApplicationContext(ctx, env, app)
+	case ApplicationVisitor:
+		return v.Application(env, app)
+	}
+	return nil
+}
+
+func visitService(ctx context.Context, visitor interface{}, app *Application, env *Environment, svc *Service) error {
+	switch v := visitor.(type) {
+	case ServiceContextVisitor:
+		return v.ServiceContext(ctx, app, env, svc)
+	case ServiceVisitor:
+		return v.Service(app, env, svc)
+	}
 	return nil
 }
 
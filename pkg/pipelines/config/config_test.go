@@ -1,12 +1,14 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"sort"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/mkmik/multierror"
 )
 
 func TestManifestWalk(t *testing.T) {
@@ -127,6 +129,135 @@ func TestManifestWalkCalls(t *testing.T) {
 		t.Fatalf("tree files: %s", diff)
 	}
 }
+func TestManifestWalkStopsAtFirstError(t *testing.T) {
+	m := &Manifest{
+		Environments: []*Environment{
+			{
+				Name: "development",
+				Apps: []*Application{
+					{
+						Name: "my-app-1",
+						Services: []*Service{
+							{Name: "app-1-service-http"},
+							{Name: "app-1-service-test"},
+						},
+					},
+				},
+			},
+		},
+	}
+	v := &failingVisitor{}
+	err := m.Walk(v)
+
+	want := []string{"service app-1-service-http failed"}
+	if diff := cmp.Diff(want, errorStrings(err)); diff != "" {
+		t.Fatalf("walk errors: %s", diff)
+	}
+	if v.environments != 0 {
+		t.Fatalf("environment visited %d times, want 0", v.environments)
+	}
+}
+
+func TestManifestWalkUntilCollectsErrors(t *testing.T) {
+	m := &Manifest{
+		Environments: []*Environment{
+			{
+				Name: "development",
+				Apps: []*Application{
+					{
+						Name: "my-app-1",
+						Services: []*Service{
+							{Name: "app-1-service-http"},
+							{Name: "app-1-service-test"},
+						},
+					},
+				},
+			},
+		},
+	}
+	v := &failingVisitor{}
+	err := m.WalkUntil(v, func(error) bool { return false })
+
+	want := []string{
+		"service app-1-service-http failed",
+		"service app-1-service-test failed",
+		"application my-app-1 failed",
+	}
+	if diff := cmp.Diff(want, errorStrings(err)); diff != "" {
+		t.Fatalf("walk errors: %s", diff)
+	}
+	if v.environments != 1 {
+		t.Fatalf("environment visited %d times, want 1", v.environments)
+	}
+}
+
+func TestManifestWalkUntil(t *testing.T) {
+	m := &Manifest{
+		Environments: []*Environment{
+			{
+				Name: "development",
+				Apps: []*Application{
+					{
+						Name: "my-app-1",
+						Services: []*Service{
+							{Name: "app-1-service-http"},
+							{Name: "app-1-service-test"},
+						},
+					},
+				},
+			},
+		},
+	}
+	v := &failingVisitor{}
+	err := m.WalkUntil(v, func(error) bool { return true })
+
+	want := []string{"service app-1-service-http failed"}
+	if diff := cmp.Diff(want, errorStrings(err)); diff != "" {
+		t.Fatalf("walk errors: %s", diff)
+	}
+	if v.environments != 0 {
+		t.Fatalf("environment visited %d times, want 0", v.environments)
+	}
+}
+
+func TestManifestWalkContext(t *testing.T) {
+	m := &Manifest{
+		Environments: []*Environment{
+			{
+				Name: "development",
+				Apps: []*Application{
+					{
+						Name: "my-app-1",
+						Services: []*Service{
+							{Name: "app-1-service-http"},
+							{Name: "app-1-service-test"},
+						},
+					},
+				},
+			},
+			{
+				Name: "staging",
+			},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), contextKey{}, "walk"))
+	defer cancel()
+	v := &contextVisitor{cancelAfter: "my-app-1", cancel: cancel}
+	err := m.WalkContext(ctx, v)
+
+	if err != context.Canceled {
+		t.Fatalf("got %v, want %v", err, context.Canceled)
+	}
+	want := []string{
+		"walk service app-1-service-http",
+		"walk service app-1-service-test",
+		"walk application my-app-1",
+	}
+	if diff := cmp.Diff(want, v.visited); diff != "" {
+		t.Fatalf("walk visited: %s", diff)
+	}
+}
+
 func TestGetPipelinesConfig(t *testing.T) {
 	cfg := &Config{
 		Pipelines: &PipelinesConfig{
@@ -234,3 +365,75 @@ func (v *testVisitor) Environment(env *Environment) error {
 	v.paths = append(v.paths, filepath.Join("envs", env.Name))
 	return nil
 }
+
+type failingVisitor struct {
+	environments int
+}
+
+func (v *failingVisitor) Service(app *Application, env *Environment, svc *Service) error {
+	return fmt.Errorf("service %s failed", svc.Name)
+}
+func (v *failingVisitor) Application(env *Environment, app *Application) error {
+	return fmt.Errorf("application %s failed", app.Name)
+}
+func (v *failingVisitor) Environment(env *Environment) error {
+	v.environments++
+	return nil
+}
+
+type contextKey struct{}
+
+// contextVisitor records the elements that it visits, with the value from the
+// context, and cancels the context after visiting the named element.
+type contextVisitor struct {
+	visited     []string
+	cancelAfter string
+	cancel      func()
+}
+
+func (v *contextVisitor) visit(ctx context.Context, kind, name string) error {
+	v.visited = append(v.visited, fmt.Sprintf("%s %s %s", ctx.Value(contextKey{}), kind, name))
+	if name == v.cancelAfter {
+		v.cancel()
+	}
+	return nil
+}
+
+func (v *contextVisitor) ServiceContext(ctx context.Context, app *Application, env *Environment, svc *Service) error {
+	return v.visit(ctx, "service", svc.Name)
+}
+func (v *contextVisitor) ApplicationContext(ctx context.Context, env *Environment, app *Application) error {
+	return v.visit(ctx, "application", app.Name)
+}
+func (v *contextVisitor) EnvironmentContext(ctx context.Context, env *Environment) error {
+	return v.visit(ctx, "environment", env.Name)
+}
+
+func errorStrings(err error) []string {
+	if err == nil {
+		return nil
+	}
+	s := []string{}
+	for _, e := range multierror.Split(err) {
+		s = append(s, e.Error())
+	}
+	return s
+}
+
+func TestArgoCDProject(t *testing.T) {
+	env := &Environment{Name: "development"}
+	staging := &Environment{Name: "staging", ArgoCDProject: "staging"}
+
+	m := &Manifest{Environments: []*Environment{env, staging}}
+	if p := m.ArgoCDProject(env); p != DefaultArgoCDProject {
+		t.Fatalf("ArgoCDProject() got %q, want %q", p, DefaultArgoCDProject)
+	}
+
+	m.Config = &Config{ArgoCD: &ArgoCDConfig{Namespace: "argocd", Project: "team-a"}}
+	if p := m.ArgoCDProject(env); p != "team-a" {
+		t.Fatalf("ArgoCDProject() got %q, want %q", p, "team-a")
+	}
+	if p := m.ArgoCDProject(staging); p != "staging" {
+		t.Fatalf("ArgoCDProject() got %q, want %q", p, "staging")
+	}
+}
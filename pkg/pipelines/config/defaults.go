@@ -0,0 +1,106 @@
+package config
+
+// ApplyDefaults returns a copy of the manifest with the defaults from the
+// config applied to the environments, the manifest is not modified.
+func (m *Manifest) ApplyDefaults() *Manifest {
+	defaults := m.defaultPipelines()
+	copied := m.Clone()
+	for _, env := range copied.Environments {
+		if env.Pipelines == nil {
+			env.Pipelines = copyPipelines(defaults)
+		}
+	}
+	return copied
+}
+
+func (m Manifest) defaultPipelines() *Pipelines {
+	if m.Config == nil || m.Config.Defaults == nil {
+		return nil
+	}
+	return m.Config.Defaults.Pipelines
+}
+
+// withDefaultPipelines returns the environment if it has pipelines, or there
+// are no defaults, otherwise it returns a copy with the default pipelines.
+func withDefaultPipelines(env *Environment, defaults *Pipelines) *Environment {
+	if env.Pipelines != nil || defaults == nil {
+		return env
+	}
+	withDefaults := *env
+	withDefaults.Pipelines = copyPipelines(defaults)
+	return &withDefaults
+}
+
+func copyEnvironment(env *Environment) *Environment {
+	copied := *env
+	copied.Pipelines = copyPipelines(env.Pipelines)
+	if env.Labels != nil {
+		copied.Labels = make(map[string]string, len(env.Labels))
+		for k, v := range env.Labels {
+			copied.Labels[k] = v
+		}
+	}
+	copied.Apps = nil
+	if env.Apps != nil {
+		copied.Apps = make([]*Application, len(env.Apps))
+		for i, app := range env.Apps {
+			copied.Apps[i] = copyApplication(app)
+		}
+	}
+	return &copied
+}
+
+func copyApplication(app *Application) *Application {
+	copied := *app
+	if app.ConfigRepo != nil {
+		repo := *app.ConfigRepo
+		copied.ConfigRepo = &repo
+	}
+	copied.Services = nil
+	if app.Services != nil {
+		copied.Services = make([]*Service, len(app.Services))
+		for i, svc := range app.Services {
+			copied.Services[i] = copyService(svc)
+		}
+	}
+	return &copied
+}
+
+func copyService(svc *Service) *Service {
+	copied := *svc
+	copied.Pipelines = copyPipelines(svc.Pipelines)
+	if svc.Webhook != nil {
+		webhook := *svc.Webhook
+		if svc.Webhook.Secret != nil {
+			secret := *svc.Webhook.Secret
+			webhook.Secret = &secret
+		}
+		copied.Webhook = &webhook
+	}
+	if svc.Env != nil {
+		copied.Env = make([]EnvVar, len(svc.Env))
+		for i, v := range svc.Env {
+			copied.Env[i] = v
+			if v.ValueFrom != nil {
+				secret := *v.ValueFrom
+				copied.Env[i].ValueFrom = &secret
+			}
+		}
+	}
+	return &copied
+}
+
+func copyPipelines(p *Pipelines) *Pipelines {
+	if p == nil {
+		return nil
+	}
+	copied := *p
+	if p.Integration != nil {
+		integration := *p.Integration
+		if p.Integration.Bindings != nil {
+			integration.Bindings = append([]string{}, p.Integration.Bindings...)
+		}
+		copied.Integration = &integration
+	}
+	return &copied
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	defaults := &Pipelines{
+		Integration: &TemplateBinding{Template: "app-ci-template", Bindings: []string{"github-push-binding"}},
+	}
+	staging := &Pipelines{
+		Integration: &TemplateBinding{Template: "dev-ci-template", Bindings: []string{"dev-ci-binding"}},
+	}
+	m := &Manifest{
+		Config: &Config{Defaults: &Defaults{Pipelines: defaults}},
+		Environments: []*Environment{
+			{Name: "development", Apps: []*Application{{Name: "my-app-1", Services: []*Service{{Name: "service-1"}}}}},
+			{Name: "staging", Pipelines: staging},
+		},
+	}
+
+	resolved := m.ApplyDefaults()
+
+	want := &Manifest{
+		Config: &Config{Defaults: &Defaults{Pipelines: defaults}},
+		Environments: []*Environment{
+			{Name: "development", Pipelines: defaults, Apps: []*Application{{Name: "my-app-1", Services: []*Service{{Name: "service-1"}}}}},
+			{Name: "staging", Pipelines: staging},
+		},
+	}
+	if diff := cmp.Diff(want, resolved); diff != "" {
+		t.Fatalf("ApplyDefaults() failed:\n%s", diff)
+	}
+	if m.Environments[0].Pipelines != nil {
+		t.Fatalf("ApplyDefaults() modified the original manifest: %#v", m.Environments[0].Pipelines)
+	}
+	resolved.Environments[0].Pipelines.Integration.Bindings[0] = "changed"
+	if defaults.Integration.Bindings[0] != "github-push-binding" {
+		t.Fatal("ApplyDefaults() shares the default bindings with the resolved manifest")
+	}
+}
+
+func TestWalkWithDefaultPipelines(t *testing.T) {
+	defaults := &Pipelines{
+		Integration: &TemplateBinding{Template: "app-ci-template", Bindings: []string{"github-push-binding"}},
+	}
+	m := &Manifest{
+		Config: &Config{Defaults: &Defaults{Pipelines: defaults}},
+		Environments: []*Environment{
+			{Name: "development", Apps: []*Application{{Name: "my-app-1", Services: []*Service{{Name: "service-1"}}}}},
+		},
+	}
+
+	visited := map[string]*Pipelines{}
+	err := m.Walk(&pipelinesVisitor{func(path string, p *Pipelines) { visited[path] = p }})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]*Pipelines{
+		"environments/development":                                  defaults,
+		"environments/development/apps/my-app-1":                    defaults,
+		"environments/development/apps/my-app-1/services/service-1": defaults,
+	}
+	if diff := cmp.Diff(want, visited); diff != "" {
+		t.Fatalf("Walk() failed:\n%s", diff)
+	}
+	if m.Environments[0].Pipelines != nil {
+		t.Fatalf("Walk() modified the manifest: %#v", m.Environments[0].Pipelines)
+	}
+}
+
+// pipelinesVisitor records the pipelines of the environment for each element.
+type pipelinesVisitor struct {
+	visit func(string, *Pipelines)
+}
+
+func (v *pipelinesVisitor) Environment(env *Environment) error {
+	v.visit(PathForEnvironment(env), env.Pipelines)
+	return nil
+}
+
+func (v *pipelinesVisitor) Application(env *Environment, app *Application) error {
+	v.visit(PathForApplication(env, app), env.Pipelines)
+	return nil
+}
+
+func (v *pipelinesVisitor) Service(app *Application, env *Environment, svc *Service) error {
+	v.visit(PathForService(app, env, svc.Name), env.Pipelines)
+	return nil
+}
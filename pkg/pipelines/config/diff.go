@@ -0,0 +1,105 @@
+package config
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// ChangeType describes how an object differs between two manifests.
+type ChangeType string
+
+const (
+	// ChangeAdded is an object that is only in the new manifest.
+	ChangeAdded ChangeType = "added"
+	// ChangeRemoved is an object that is only in the old manifest.
+	ChangeRemoved ChangeType = "removed"
+	// ChangeModified is an object that is in both manifests, with different
+	// fields.
+	ChangeModified ChangeType = "modified"
+)
+
+// Change is an environment, application or service that differs between two
+// manifests.
+type Change struct {
+	Type ChangeType
+	// Path identifies the object e.g. environments.dev.apps.my-app
+	Path string
+	// Before and After summarize the fields of the object, excluding the
+	// objects it contains, they are empty if the object was added or removed.
+	Before string
+	After  string
+}
+
+// Diff compares two manifests, and returns the environments, applications and
+// services that were added, removed or modified, ordered by path.
+func Diff(old, new *Manifest) ([]Change, error) {
+	before, err := summarize(old)
+	if err != nil {
+		return nil, err
+	}
+	after, err := summarize(new)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []Change{}
+	for path, b := range before {
+		a, ok := after[path]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Type: ChangeRemoved, Path: path, Before: b})
+		case a != b:
+			changes = append(changes, Change{Type: ChangeModified, Path: path, Before: b, After: a})
+		}
+	}
+	for path, a := range after {
+		if _, ok := before[path]; !ok {
+			changes = append(changes, Change{Type: ChangeAdded, Path: path, After: a})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes, nil
+}
+
+// summarize returns a summary of each object in the manifest, keyed by path.
+func summarize(m *Manifest) (map[string]string, error) {
+	sv := &summaryVisitor{summaries: map[string]string{}}
+	if m == nil {
+		return sv.summaries, nil
+	}
+	if err := m.Walk(sv); err != nil {
+		return nil, err
+	}
+	return sv.summaries, nil
+}
+
+type summaryVisitor struct {
+	summaries map[string]string
+}
+
+func (sv *summaryVisitor) Environment(env *Environment) error {
+	shallow := *env
+	shallow.Apps = nil
+	return sv.add(yamlPath(PathForEnvironment(env)), shallow)
+}
+
+func (sv *summaryVisitor) Application(env *Environment, app *Application) error {
+	shallow := *app
+	shallow.Services = nil
+	return sv.add(yamlPath(PathForApplication(env, app)), shallow)
+}
+
+func (sv *summaryVisitor) Service(app *Application, env *Environment, svc *Service) error {
+	return sv.add(yamlPath(PathForService(app, env, svc.Name)), svc)
+}
+
+func (sv *summaryVisitor) add(path string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	sv.summaries[path] = string(b)
+	return nil
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestDiff(t *testing.T) {
+	fs := ioutils.NewFilesystem()
+	old, err := ParseFile(fs, "testdata/diff_old.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	new, err := ParseFile(fs, "testdata/diff_new.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := Diff(old, new)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Change{
+		{
+			Type:   ChangeModified,
+			Path:   "environments.development.apps.my-app-1.services.service-http",
+			Before: `{"name":"service-http","source_url":"https://github.com/myproject/myservice.git"}`,
+			After:  `{"name":"service-http","source_url":"https://github.com/myproject/myservice2.git"}`,
+		},
+		{
+			Type:   ChangeRemoved,
+			Path:   "environments.development.apps.my-app-1.services.service-metrics",
+			Before: `{"name":"service-metrics"}`,
+		},
+		{
+			Type:   ChangeModified,
+			Path:   "environments.staging",
+			Before: `{"name":"staging"}`,
+			After:  `{"name":"staging","cluster":"https://staging.example.com"}`,
+		},
+		{
+			Type:  ChangeAdded,
+			Path:  "environments.staging.apps.my-app-2",
+			After: `{"name":"my-app-2"}`,
+		},
+		{
+			Type:  ChangeAdded,
+			Path:  "environments.staging.apps.my-app-2.services.service-redis",
+			After: `{"name":"service-redis"}`,
+		},
+	}
+	if diff := cmp.Diff(want, changes); diff != "" {
+		t.Fatalf("Diff() failed:\n%s", diff)
+	}
+}
+
+func TestDiffWithNoChanges(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/example1.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := Diff(m, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("Diff() got %#v, want no changes", changes)
+	}
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mkmik/multierror"
+	"knative.dev/pkg/apis"
+)
+
+// ValidationIssue is a machine-readable representation of a single problem
+// found by validating a manifest.
+type ValidationIssue struct {
+	Message  string   `json:"message"`
+	Details  string   `json:"details,omitempty"`
+	Paths    []string `json:"paths,omitempty"`
+	Severity Severity `json:"severity"`
+}
+
+// FormatValidationErrors converts the error returned from Validate into a set of
+// ValidationIssues, suitable for serializing to JSON.
+//
+// Errors that are not field errors are converted to issues with only a
+// message.
+func FormatValidationErrors(err error) ([]ValidationIssue, error) {
+	issues := []ValidationIssue{}
+	if err == nil {
+		return issues, nil
+	}
+	for _, e := range flattenErrors(err) {
+		issue := ValidationIssue{Message: e.Error(), Severity: SeverityError}
+		var fe *apis.FieldError
+		if errors.As(e, &fe) {
+			issue.Message = fe.Message
+			issue.Details = fe.Details
+			issue.Paths = fe.Paths
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// flattenErrors splits nested multi-errors into a single list.
+func flattenErrors(err error) []error {
+	errs := multierror.Split(err)
+	if len(errs) == 1 && errs[0] == err {
+		return errs
+	}
+	flat := []error{}
+	for _, e := range errs {
+		flat = append(flat, flattenErrors(e)...)
+	}
+	return flat
+}
+
+// generalGroup is the group for errors that don't identify a path in the
+// manifest.
+const generalGroup = "general"
+
+// FormatGrouped formats the errors from validating a manifest as a tree, with
+// the errors for each environment, application and service grouped together.
+//
+// Errors that are not *apis.FieldErrors are listed in a "general" group at the
+// end.
+//
+//	environments.development
+//	  apps.app-1
+//	    services.service-1
+//	      - missing field(s) "secret" (webhook)
+//	general
+//	  - unable to identify driver from hostname: git.example.com
+func FormatGrouped(err error) string {
+	if err == nil {
+		return ""
+	}
+	root := &errorGroup{children: map[string]*errorGroup{}}
+	general := []string{}
+	for _, e := range multierror.Split(err) {
+		var fe *apis.FieldError
+		if !errors.As(e, &fe) || len(fe.Paths) == 0 {
+			general = append(general, e.Error())
+			continue
+		}
+		object, field := splitObjectPath(fe.Paths[0])
+		group := root
+		for _, name := range object {
+			group = group.child(name)
+		}
+		group.messages = append(group.messages, formatFieldError(fe, field))
+	}
+
+	var sb strings.Builder
+	root.write(&sb, -1)
+	if len(general) > 0 {
+		sb.WriteString(generalGroup + "\n")
+		for _, msg := range general {
+			writeMessage(&sb, 1, msg)
+		}
+	}
+	return sb.String()
+}
+
+// errorGroup is a node in the tree of errors.
+type errorGroup struct {
+	messages []string
+	children map[string]*errorGroup
+}
+
+func (g *errorGroup) child(name string) *errorGroup {
+	c, ok := g.children[name]
+	if !ok {
+		c = &errorGroup{children: map[string]*errorGroup{}}
+		g.children[name] = c
+	}
+	return c
+}
+
+// write writes the messages of the group, and then the groups that it
+// contains, sorted by name.
+func (g *errorGroup) write(sb *strings.Builder, depth int) {
+	for _, msg := range g.messages {
+		writeMessage(sb, depth+1, msg)
+	}
+	names := []string{}
+	for name := range g.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(sb, "%s%s\n", indent(depth+1), name)
+		g.children[name].write(sb, depth+1)
+	}
+}
+
+func writeMessage(sb *strings.Builder, depth int, msg string) {
+	lines := strings.Split(msg, "\n")
+	fmt.Fprintf(sb, "%s- %s\n", indent(depth), lines[0])
+	for _, line := range lines[1:] {
+		fmt.Fprintf(sb, "%s  %s\n", indent(depth), line)
+	}
+}
+
+func indent(depth int) string {
+	return strings.Repeat("  ", depth)
+}
+
+// formatFieldError returns the message and details of the error, with the
+// field within the object, and any other paths.
+func formatFieldError(fe *apis.FieldError, field string) string {
+	msg := fe.Message
+	if field != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, field)
+	}
+	if len(fe.Paths) > 1 {
+		msg = fmt.Sprintf("%s, also at %s", msg, strings.Join(fe.Paths[1:], ", "))
+	}
+	if fe.Details != "" {
+		msg = msg + "\n" + fe.Details
+	}
+	return msg
+}
+
+// objectPathTypes are the types of the objects in the paths of the errors, and
+// the field that holds the objects they contain.
+var objectPathTypes = []struct {
+	fields   map[string]bool
+	children string
+}{
+	{fields: jsonFields(reflect.TypeOf(Environment{})), children: "apps"},
+	{fields: jsonFields(reflect.TypeOf(Application{})), children: "services"},
+	{fields: jsonFields(reflect.TypeOf(Service{}))},
+}
+
+// splitObjectPath splits a path into the environment, application and service
+// that it identifies, and the field within the deepest of these.
+//
+// Paths outside of the environments are grouped by their first element e.g.
+// config.argocd is the argocd field of the config.
+func splitObjectPath(path string) ([]string, string) {
+	elements := strings.Split(path, ".")
+	if elements[0] != "environments" || len(elements) == 1 {
+		return elements[:1], strings.Join(elements[1:], ".")
+	}
+	objects := []string{}
+	collection, start := elements[0], 1
+	for _, t := range objectPathTypes {
+		// Names can contain dots, so the name extends to the next field.
+		end := start + 1
+		for end < len(elements) && !t.fields[elements[end]] {
+			end++
+		}
+		objects = append(objects, collection+"."+strings.Join(elements[start:end], "."))
+		if t.children == "" || end+1 >= len(elements) || elements[end] != t.children {
+			return objects, strings.Join(elements[end:], ".")
+		}
+		collection, start = elements[end], end+1
+	}
+	return objects, ""
+}
+
+func jsonFields(t reflect.Type) map[string]bool {
+	fields := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonName(t.Field(i)); name != "" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mkmik/multierror"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+)
+
+func TestFormatValidationErrors(t *testing.T) {
+	err := multierror.Join(
+		[]error{
+			invalidNameError("argo.cd", DNS1035Error, []string{"config.argocd"}),
+			multierror.Join([]error{
+				missingFieldsError([]string{"secret"}, []string{"environments.development.apps.app-1.services.service-1.webhook"}),
+			}),
+			errors.New("failed to walk"),
+		},
+	)
+
+	issues, err := FormatValidationErrors(err)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []ValidationIssue{
+		{Message: `invalid name "argo.cd"`, Details: DNS1035Error, Paths: []string{"config.argocd"}, Severity: SeverityError},
+		{Message: `missing field(s) "secret"`, Paths: []string{"environments.development.apps.app-1.services.service-1.webhook"}, Severity: SeverityError},
+		{Message: "failed to walk", Severity: SeverityError},
+	}
+	if diff := cmp.Diff(want, issues); diff != "" {
+		t.Fatalf("issues did not match:\n%s", diff)
+	}
+}
+
+func TestFormatValidationErrorsWithNoError(t *testing.T) {
+	issues, err := FormatValidationErrors(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "[]" {
+		t.Fatalf("got %s, want []", b)
+	}
+}
+
+func TestFormatGrouped(t *testing.T) {
+	err := multierror.Join([]error{
+		invalidNameError("argo.cd", "invalid", []string{"config.argocd"}),
+		missingFieldsError([]string{"secret"}, []string{"environments.development.apps.app-1.services.service-1.webhook"}),
+		invalidNameError("develo.pment", "invalid", []string{"environments.develo.pment"}),
+		missingFieldsError([]string{"integration"}, []string{"environments.development.apps.app-1.services.service-1.pipelines"}),
+		invalidNameError("", "invalid", []string{"environments.develo.pment.apps.app-1$.services"}),
+		&scm.UnknownDriverError{Host: "git.example.com", URL: "https://git.example.com/org/gitops.git"},
+		duplicateSourceError("https://github.com/org/repo", []string{
+			"environments.development.apps.app-1.services.service-1",
+			"environments.development.apps.app-2.services.service-2"}),
+		missingFieldsError([]string{"services", "config_repo"}, []string{"environments.development.apps.app-3"}),
+	})
+
+	want := `config
+  - invalid name "argo.cd" (argocd)
+    invalid
+environments.develo.pment
+  - invalid name "develo.pment"
+    invalid
+  apps.app-1$
+    - invalid name "" (services)
+      invalid
+environments.development
+  apps.app-1
+    services.service-1
+      - missing field(s) "secret" (webhook)
+      - missing field(s) "integration" (pipelines)
+      - duplicate source detected, multiple services cannot share the same source repository: https://github.com/org/repo, also at environments.development.apps.app-2.services.service-2
+  apps.app-3
+    - missing field(s) "services","config_repo"
+general
+  - unable to identify driver from hostname: git.example.com
+`
+	if diff := cmp.Diff(want, FormatGrouped(err)); diff != "" {
+		t.Fatalf("FormatGrouped() failed:\n%s", diff)
+	}
+}
+
+func TestFormatGroupedWithNoErrors(t *testing.T) {
+	if s := FormatGrouped(nil); s != "" {
+		t.Fatalf("FormatGrouped(nil) got %q, want an empty string", s)
+	}
+}
+
+func TestSplitObjectPath(t *testing.T) {
+	pathTests := []struct {
+		path        string
+		wantObjects []string
+		wantField   string
+	}{
+		{"gitops_url", []string{"gitops_url"}, ""},
+		{"config.pipelines.name", []string{"config"}, "pipelines.name"},
+		{"environments.dev", []string{"environments.dev"}, ""},
+		{"environments.dev.pipelines.integration.binding", []string{"environments.dev"}, "pipelines.integration.binding"},
+		{"environments.dev.apps.app-1.config_repo.url", []string{"environments.dev", "apps.app-1"}, "config_repo.url"},
+		{"environments.dev.apps.app-1.services.svc.webhook.secret.key", []string{"environments.dev", "apps.app-1", "services.svc"}, "webhook.secret.key"},
+		{"environments.0.apps.1.services.2.name", []string{"environments.0", "apps.1", "services.2"}, "name"},
+		{"environments.develo.pment.apps", []string{"environments.develo.pment"}, "apps"},
+	}
+
+	for _, tt := range pathTests {
+		objects, field := splitObjectPath(tt.path)
+		if diff := cmp.Diff(tt.wantObjects, objects); diff != "" {
+			t.Errorf("splitObjectPath(%q) objects did not match:\n%s", tt.path, diff)
+		}
+		if field != tt.wantField {
+			t.Errorf("splitObjectPath(%q) got field %q, want %q", tt.path, field, tt.wantField)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package config
+
+// ManifestIndex records the relationships between the objects in a manifest.
+type ManifestIndex struct {
+	// Environments are the names of the environments in the manifest.
+	Environments []string
+	// ServiceEnvironments maps service names to the environments that they
+	// are deployed to.
+	ServiceEnvironments map[string][]string
+	// SourceURLs maps service source URLs to the paths of the services that
+	// are built from them.
+	SourceURLs map[string][]string
+	// TruncatedServiceNames maps long service names to their truncated names,
+	// when the manifest is indexed WithServiceNameTruncation.
+	TruncatedServiceNames map[string]string
+}
+
+// Index returns the relationships between the objects in the manifest, these
+// are recorded while validating the manifest, and an error is returned if the
+// manifest is invalid.
+func (m *Manifest) Index(opts ...ValidateOption) (*ManifestIndex, error) {
+	vv := m.validate(opts...)
+	if err := vv.err(); err != nil {
+		return nil, err
+	}
+	return &ManifestIndex{
+		Environments:        vv.environments,
+		ServiceEnvironments: vv.serviceEnvironments,
+		SourceURLs:          vv.serviceURLs,
+
+		TruncatedServiceNames: vv.truncatedNames,
+	}, nil
+}
+
+// EnvironmentsFor returns the names of the environments that the named service
+// is deployed to.
+func (mi *ManifestIndex) EnvironmentsFor(service string) []string {
+	return mi.ServiceEnvironments[service]
+}
+
+// SetSourceURL sets the source URL of the service to the URL, and returns an
+// error if another service in the index is already built from the same
+// repository, the URLs are compared in their canonical form.
+//
+// The index is not updated, as it records services by their path in the
+// manifest, which the service doesn't know.
+func (s *Service) SetSourceURL(url string, index *ManifestIndex) error {
+	canonical := canonicalGitURL(url)
+	if s.SourceURL != "" && canonicalGitURL(s.SourceURL) == canonical {
+		s.SourceURL = url
+		return nil
+	}
+	if index != nil {
+		if paths := index.SourceURLs[canonical]; len(paths) > 0 {
+			return duplicateSourceError(canonical, paths)
+		}
+	}
+	s.SourceURL = url
+	return nil
+}
+
+// SourceRepositories returns the distinct source URLs of the services in the
+// manifest, mapped to the paths of the services that are built from them.
+//
+// The URLs are canonicalized in the same way as when detecting duplicate
+// source URLs.
+func (m *Manifest) SourceRepositories() map[string][]string {
+	sv := sourcesVisitor{repos: sourceRepositories{}}
+	// The visitor does not return errors.
+	_ = m.Walk(sv)
+	return sv.repos
+}
+
+// sourceRepositories maps canonical source URLs to the paths of the services
+// that are built from them.
+type sourceRepositories map[string][]string
+
+// add records the path for the source URL, and returns the canonical URL.
+func (r sourceRepositories) add(rawURL, path string) string {
+	url := canonicalGitURL(rawURL)
+	r[url] = append(r[url], path)
+	return url
+}
+
+type sourcesVisitor struct {
+	repos sourceRepositories
+}
+
+func (sv sourcesVisitor) Service(app *Application, env *Environment, svc *Service) error {
+	if svc.SourceURL != "" {
+		sv.repos.add(svc.SourceURL, yamlPath(PathForService(app, env, svc.Name)))
+	}
+	return nil
+}
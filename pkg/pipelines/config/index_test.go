@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestIndex(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/example1.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	index, err := m.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &ManifestIndex{
+		Environments: []string{"development", "production", "staging"},
+		ServiceEnvironments: map[string][]string{
+			"service-http":    {"development", "production"},
+			"service-redis":   {"development"},
+			"service-metrics": {"production"},
+		},
+		SourceURLs: map[string][]string{
+			"https://github.com/myproject/myservice": {"environments.development.apps.my-app-1.services.service-http"},
+		},
+	}
+	if diff := cmp.Diff(want, index); diff != "" {
+		t.Fatalf("index did not match:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"development", "production"}, index.EnvironmentsFor("service-http")); diff != "" {
+		t.Fatalf("environments did not match:\n%s", diff)
+	}
+}
+
+func TestIndexWithInvalidManifest(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/duplicate_service.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	index, err := m.Index()
+	if err == nil {
+		t.Fatalf("Index() returned an index for an invalid manifest: %#v", index)
+	}
+}
+
+func TestServiceSetSourceURL(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/example1.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	index, err := m.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := &Service{Name: "new-service"}
+	err = svc.SetSourceURL("git@github.com:myproject/myservice.git", index)
+	want := duplicateSourceError("https://github.com/myproject/myservice",
+		[]string{"environments.development.apps.my-app-1.services.service-http"})
+	if diff := cmp.Diff(want.Error(), fmt.Sprint(err)); diff != "" {
+		t.Fatalf("error did not match:\n%s", diff)
+	}
+	if svc.SourceURL != "" {
+		t.Fatalf("source URL was set to %q for a duplicate source", svc.SourceURL)
+	}
+
+	if err := svc.SetSourceURL("git@github.com:myproject/other-service.git", index); err != nil {
+		t.Fatal(err)
+	}
+	if want := "git@github.com:myproject/other-service.git"; svc.SourceURL != want {
+		t.Fatalf("got source URL %q, want %q", svc.SourceURL, want)
+	}
+
+	existing := m.GetEnvironment("development").Apps[0].Services[0]
+	if err := existing.SetSourceURL("https://github.com/myproject/myservice.git", index); err != nil {
+		t.Fatalf("setting the source URL of a service to its own URL failed: %v", err)
+	}
+	if want := "https://github.com/myproject/myservice.git"; existing.SourceURL != want {
+		t.Fatalf("got source URL %q, want %q", existing.SourceURL, want)
+	}
+}
+
+func TestSourceRepositories(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/duplicate_source_url_spellings.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+
+	want := map[string][]string{
+		"https://github.com/testing/testing": {
+			"environments.duplicate-source.apps.my-app-1.services.app-1-service-http",
+			"environments.duplicate-source.apps.my-app-2.services.app-2-service-http",
+		},
+	}
+	if diff := cmp.Diff(want, m.SourceRepositories()); diff != "" {
+		t.Fatalf("source repositories did not match:\n%s", diff)
+	}
+}
@@ -1,5 +1,7 @@
 package config
 
+import "context"
+
 // EnvironmentVisitor is an interface for accessing environments from the manifest.
 type EnvironmentVisitor interface {
 	Environment(*Environment) error
@@ -14,3 +16,34 @@ type ApplicationVisitor interface {
 type ServiceVisitor interface {
 	Service(*Application, *Environment, *Service) error
 }
+
+// EnvironmentContextVisitor is an interface for accessing environments from the
+// manifest with the context passed to Manifest.WalkContext, it is used in
+// preference to EnvironmentVisitor.
+type EnvironmentContextVisitor interface {
+	EnvironmentContext(context.Context, *Environment) error
+}
+
+// ApplicationContextVisitor is an interface for accessing applications from the
+// manifest with the context passed to Manifest.WalkContext, it is used in
+// preference to ApplicationVisitor.
+type ApplicationContextVisitor interface {
+	ApplicationContext(context.Context, *Environment, *Application) error
+}
+
+// ServiceContextVisitor is an interface for accessing services from the
+// manifest with the context passed to Manifest.WalkContext, it is used in
+// preference to ServiceVisitor.
+type ServiceContextVisitor interface {
+	ServiceContext(context.Context, *Application, *Environment, *Service) error
+}
+
+// ManifestValidator is an interface for adding custom validation rules to
+// Manifest.Validate, it is called for every environment, application and
+// service in the manifest, and the errors it returns are reported together
+// with the errors from the core validation.
+type ManifestValidator interface {
+	EnvironmentVisitor
+	ApplicationVisitor
+	ServiceVisitor
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"knative.dev/pkg/apis"
+)
+
+// Limits are the maximum numbers of objects in a manifest, they protect the
+// tools that process the manifest from manifests that are generated with far
+// more objects than intended.
+//
+// A limit of zero means that there is no limit.
+type Limits struct {
+	// Environments is the maximum number of environments in the manifest.
+	Environments int
+	// ApplicationsPerEnvironment is the maximum number of applications in each
+	// environment.
+	ApplicationsPerEnvironment int
+	// ServicesPerApplication is the maximum number of services in each
+	// application.
+	ServicesPerApplication int
+}
+
+// DefaultLimits are the limits that Walk enforces, and that manifests are
+// validated with, unless they are validated WithLimits.
+var DefaultLimits = Limits{
+	Environments:               1000,
+	ApplicationsPerEnvironment: 1000,
+	ServicesPerApplication:     1000,
+}
+
+// WithLimits validates the manifest with the limits, rather than the
+// DefaultLimits.
+func WithLimits(limits Limits) ValidateOption {
+	return func(vv *validateVisitor) {
+		vv.limits = limits
+	}
+}
+
+// walk walks the visitor over the manifest, in the same way as Manifest.Walk,
+// with the limits of the validation.
+func (vv *validateVisitor) walk(m *Manifest, visitor interface{}) error {
+	return m.walk(context.Background(), visitor, vv.limits, neverStop)
+}
+
+// check returns an error for the first environment, application, or list of
+// services that exceeds the limits, the environments must already be sorted.
+func (l Limits) check(m *Manifest) error {
+	if err := checkLimit("environments", len(m.Environments), l.Environments, "environments"); err != nil {
+		return err
+	}
+	for _, env := range m.Environments {
+		envPath := yamlPath(PathForEnvironment(env))
+		if err := checkLimit("applications", len(env.Apps), l.ApplicationsPerEnvironment, yamlJoin(envPath, "apps")); err != nil {
+			return err
+		}
+		for _, app := range env.Apps {
+			appPath := yamlPath(PathForApplication(env, app))
+			if err := checkLimit("services", len(app.Services), l.ServicesPerApplication, yamlJoin(appPath, "services")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkLimit(kind string, count, limit int, path string) error {
+	if limit > 0 && count > limit {
+		return limitExceededError(kind, count, limit, []string{path})
+	}
+	return nil
+}
+
+func limitExceededError(kind string, count, limit int, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("too many %s: %d, the limit is %d", kind, count, limit),
+		Details: "the manifest is not processed, as it may have been generated incorrectly",
+		Paths:   paths,
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestValidateWithLimits(t *testing.T) {
+	limitTests := []struct {
+		desc    string
+		limits  Limits
+		wantErr error
+	}{
+		{"within the limits", Limits{Environments: 3, ApplicationsPerEnvironment: 3, ServicesPerApplication: 3}, nil},
+		{"no limits", Limits{}, nil},
+		{
+			"too many environments", Limits{Environments: 2},
+			limitExceededError("environments", 3, 2, []string{"environments"}),
+		},
+		{
+			"too many applications", Limits{ApplicationsPerEnvironment: 2},
+			limitExceededError("applications", 3, 2, []string{"environments.development.apps"}),
+		},
+		{
+			"too many services", Limits{ServicesPerApplication: 2},
+			limitExceededError("services", 3, 2, []string{"environments.development.apps.app-1.services"}),
+		},
+	}
+
+	for _, tt := range limitTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			m, err := ParseFile(ioutils.NewFilesystem(), "testdata/limits.yaml")
+			if err != nil {
+				rt.Fatalf("failed to parse file:%v", err)
+			}
+			if err := matchMultiErrors(rt, m.Validate(WithLimits(tt.limits)), tt.wantErr); err != nil {
+				rt.Fatal(err)
+			}
+			if err := matchMultiErrors(rt, m.Validate(WithLimits(tt.limits), WithConcurrency(2)), tt.wantErr); err != nil {
+				rt.Fatalf("concurrent validation: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateStreamWithLimits(t *testing.T) {
+	f, err := os.Open("testdata/limits.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	want := limitExceededError("environments", 3, 1, []string{"environments"})
+	if err := matchMultiErrors(t, ValidateStream(f, nil, WithLimits(Limits{Environments: 1})), want); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWalkWithDefaultLimits(t *testing.T) {
+	defer func(l Limits) {
+		DefaultLimits = l
+	}(DefaultLimits)
+	DefaultLimits = Limits{Environments: 2}
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/limits.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	visitor := &countingVisitor{}
+
+	err = m.Walk(visitor)
+
+	want := limitExceededError("environments", 3, 2, []string{"environments"})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+	if len(visitor.Environments)+len(visitor.Applications)+len(visitor.Services) > 0 {
+		t.Fatalf("walk visited the elements of a manifest that exceeded the limits: %#v", visitor)
+	}
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mkmik/multierror"
+	"knative.dev/pkg/apis"
+)
+
+// defaultConfigRepoPath is the path that is set when a config_repo has no
+// path, it is the root of the repository.
+const defaultConfigRepoPath = "."
+
+// LintFinding is a problem found by Lint.
+type LintFinding struct {
+	// Message describes the problem.
+	Message string
+	// Path is the path of the problem in the manifest.
+	Path string
+	// Fix modifies the manifest to resolve the problem, it is nil if the
+	// problem can't be fixed automatically.
+	Fix func(*Manifest) error
+}
+
+// Lint returns the problems found by validating the manifest, and the problems
+// that can be fixed automatically, e.g. names with leading or trailing
+// whitespace.
+//
+// Problems that can be fixed are reported once, with a Fix, rather than as a
+// validation error. Lint does not change the manifest, the fixes are applied by
+// calling them, and the manifest can then be validated again.
+func Lint(m *Manifest) []LintFinding {
+	// Validating the manifest sorts the environments, so the fixes find the
+	// objects by their position after validation.
+	err, warnings := m.ValidateWithWarnings()
+	findings := lintFixes(m)
+	fixed := map[string]bool{}
+	for _, f := range findings {
+		fixed[f.Path] = true
+	}
+	for _, err := range multierror.Split(err) {
+		message, paths := err.Error(), []string{}
+		var fe *apis.FieldError
+		if errors.As(err, &fe) {
+			message, paths = fe.Message, fe.Paths
+		}
+		if !anyFixed(paths, fixed) {
+			findings = append(findings, LintFinding{Message: message, Path: strings.Join(paths, ", ")})
+		}
+	}
+	for _, w := range warnings {
+		path, message := "", w
+		if i := strings.Index(w, ": "); i >= 0 {
+			path, message = w[:i], w[i+2:]
+		}
+		if !fixed[path] {
+			findings = append(findings, LintFinding{Message: message, Path: path})
+		}
+	}
+	return findings
+}
+
+func anyFixed(paths []string, fixed map[string]bool) bool {
+	for _, path := range paths {
+		if fixed[path] {
+			return true
+		}
+	}
+	return false
+}
+
+// lintFixes returns the problems in the manifest that can be fixed.
+func lintFixes(m *Manifest) []LintFinding {
+	findings := []LintFinding{}
+	for i, env := range m.Environments {
+		findings = append(findings, lintEnvironment(env, i)...)
+		for j, app := range env.Apps {
+			findings = append(findings, lintApplication(env, app, i, j)...)
+			for k, svc := range app.Services {
+				findings = append(findings, lintService(env, app, svc, i, j, k)...)
+			}
+		}
+	}
+	return findings
+}
+
+func lintEnvironment(env *Environment, i int) []LintFinding {
+	findings := []LintFinding{}
+	if hasWhitespace(env.Name) {
+		findings = append(findings, LintFinding{
+			Message: fmt.Sprintf("environment name %q has leading or trailing whitespace", env.Name),
+			Path:    yamlPath(PathForEnvironment(env)),
+			Fix: func(m *Manifest) error {
+				env, err := environmentAt(m, i)
+				if err != nil {
+					return err
+				}
+				env.Name = strings.TrimSpace(env.Name)
+				return nil
+			},
+		})
+	}
+	return findings
+}
+
+func lintApplication(env *Environment, app *Application, i, j int) []LintFinding {
+	findings := []LintFinding{}
+	appPath := yamlPath(PathForApplication(env, app))
+	if hasWhitespace(app.Name) {
+		findings = append(findings, LintFinding{
+			Message: fmt.Sprintf("application name %q has leading or trailing whitespace", app.Name),
+			Path:    appPath,
+			Fix: func(m *Manifest) error {
+				app, err := applicationAt(m, i, j)
+				if err != nil {
+					return err
+				}
+				app.Name = strings.TrimSpace(app.Name)
+				return nil
+			},
+		})
+	}
+	if app.ConfigRepo != nil && app.ConfigRepo.Path == "" {
+		findings = append(findings, LintFinding{
+			Message: fmt.Sprintf("config_repo of application %q has no path, it can be set to %q for the root of the repository", app.Name, defaultConfigRepoPath),
+			Path:    yamlJoin(appPath, "config_repo"),
+			Fix: func(m *Manifest) error {
+				app, err := applicationAt(m, i, j)
+				if err != nil {
+					return err
+				}
+				if app.ConfigRepo != nil && app.ConfigRepo.Path == "" {
+					app.ConfigRepo.Path = defaultConfigRepoPath
+				}
+				return nil
+			},
+		})
+	}
+	return findings
+}
+
+func lintService(env *Environment, app *Application, svc *Service, i, j, k int) []LintFinding {
+	findings := []LintFinding{}
+	svcPath := yamlPath(PathForService(app, env, svc.Name))
+	if hasWhitespace(svc.Name) {
+		findings = append(findings, LintFinding{
+			Message: fmt.Sprintf("service name %q has leading or trailing whitespace", svc.Name),
+			Path:    svcPath,
+			Fix: func(m *Manifest) error {
+				svc, err := serviceAt(m, i, j, k)
+				if err != nil {
+					return err
+				}
+				svc.Name = strings.TrimSpace(svc.Name)
+				return nil
+			},
+		})
+	}
+	if hasWhitespace(svc.SourceURL) {
+		findings = append(findings, LintFinding{
+			Message: fmt.Sprintf("source_url %q has leading or trailing whitespace", svc.SourceURL),
+			Path:    yamlJoin(svcPath, "source_url"),
+			Fix: func(m *Manifest) error {
+				svc, err := serviceAt(m, i, j, k)
+				if err != nil {
+					return err
+				}
+				svc.SourceURL = strings.TrimSpace(svc.SourceURL)
+				return nil
+			},
+		})
+	}
+	return findings
+}
+
+func hasWhitespace(s string) bool {
+	return s != strings.TrimSpace(s)
+}
+
+// environmentAt, applicationAt and serviceAt find the objects that
+// the fixes apply to by their position, as the fixes can change their names.
+func environmentAt(m *Manifest, i int) (*Environment, error) {
+	if i >= len(m.Environments) {
+		return nil, fmt.Errorf("no environment at position %d", i)
+	}
+	return m.Environments[i], nil
+}
+
+func applicationAt(m *Manifest, i, j int) (*Application, error) {
+	env, err := environmentAt(m, i)
+	if err != nil {
+		return nil, err
+	}
+	if j >= len(env.Apps) {
+		return nil, fmt.Errorf("no application at position %d in environment %q", j, env.Name)
+	}
+	return env.Apps[j], nil
+}
+
+func serviceAt(m *Manifest, i, j, k int) (*Service, error) {
+	app, err := applicationAt(m, i, j)
+	if err != nil {
+		return nil, err
+	}
+	if k >= len(app.Services) {
+		return nil, fmt.Errorf("no service at position %d in application %q", k, app.Name)
+	}
+	return app.Services[k], nil
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestLint(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/lint.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+
+	findings := Lint(m)
+	want := []LintFinding{
+		{
+			Message: `environment name "development " has leading or trailing whitespace`,
+			Path:    "environments.development ",
+		},
+		{
+			Message: `service name " service-1" has leading or trailing whitespace`,
+			Path:    "environments.development .apps.app-1.services. service-1",
+		},
+		{
+			Message: `source_url "https://github.com/myproject/service-1.git " has leading or trailing whitespace`,
+			Path:    "environments.development .apps.app-1.services. service-1.source_url",
+		},
+		{
+			Message: `config_repo of application "app-2" has no path, it can be set to "." for the root of the repository`,
+			Path:    "environments.development .apps.app-2.config_repo",
+		},
+		{
+			Message: `invalid name "service_3"`,
+			Path:    "environments.staging.apps.app-3.services.service_3",
+		},
+	}
+	if diff := cmp.Diff(want, findings, cmpopts.IgnoreFields(LintFinding{}, "Fix")); diff != "" {
+		t.Fatalf("findings did not match:\n%s", diff)
+	}
+	for _, f := range findings[:4] {
+		if f.Fix == nil {
+			t.Fatalf("finding %q has no fix", f.Message)
+		}
+		if err := f.Fix(m); err != nil {
+			t.Fatalf("fixing %q failed: %s", f.Message, err)
+		}
+	}
+	if findings[4].Fix != nil {
+		t.Fatalf("finding %q has a fix", findings[4].Message)
+	}
+
+	fixed := Lint(m)
+	want = []LintFinding{
+		{
+			Message: `invalid name "service_3"`,
+			Path:    "environments.staging.apps.app-3.services.service_3",
+		},
+	}
+	if diff := cmp.Diff(want, fixed, cmpopts.IgnoreFields(LintFinding{}, "Fix")); diff != "" {
+		t.Fatalf("findings after fixing did not match:\n%s", diff)
+	}
+	if m.Environments[0].Apps[1].ConfigRepo.Path != "." {
+		t.Fatalf("config_repo path got %q, want %q", m.Environments[0].Apps[1].ConfigRepo.Path, ".")
+	}
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	goscm "github.com/jenkins-x/go-scm/scm"
+	"github.com/spf13/afero"
+)
+
+// remoteManifests caches the content of the manifests loaded from Git
+// repositories at a commit for the lifetime of the process.
+var remoteManifests = &manifestCache{
+	refs:     map[string]string{},
+	contents: map[string][]byte{},
+}
+
+// manifestCache maps remote manifest references to the hash of their content,
+// and the hashes to the content, so that identical manifests are only stored
+// once.
+type manifestCache struct {
+	sync.Mutex
+	refs     map[string]string
+	contents map[string][]byte
+}
+
+func (c *manifestCache) get(key string) ([]byte, bool) {
+	c.Lock()
+	defer c.Unlock()
+	hash, ok := c.refs[key]
+	if !ok {
+		return nil, false
+	}
+	return c.contents[hash], true
+}
+
+func (c *manifestCache) add(key string, data []byte) {
+	c.Lock()
+	defer c.Unlock()
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	c.refs[key] = hash
+	if _, ok := c.contents[hash]; !ok {
+		c.contents[hash] = data
+	}
+}
+
+// RemoteManifestRef identifies a manifest in a Git repository, it is parsed
+// from provider://owner/repo/path@ref e.g.
+// github://org/gitops/pipelines.yaml@main.
+type RemoteManifestRef struct {
+	// Provider is the name of the go-scm driver for the repository e.g.
+	// github.
+	Provider string
+	// Repo is the full name of the repository e.g. org/gitops.
+	Repo string
+	// Path is the path of the manifest in the repository, if it is a
+	// directory, the PipelinesFile in the directory is loaded.
+	Path string
+	// Ref is the branch, tag or commit to load the manifest from, if this is
+	// empty, the default branch is used.
+	Ref string
+}
+
+// String returns the reference in the form that it is parsed from.
+func (r RemoteManifestRef) String() string {
+	s := fmt.Sprintf("%s://%s/%s", r.Provider, r.Repo, r.Path)
+	if r.Ref != "" {
+		s += "@" + r.Ref
+	}
+	return s
+}
+
+// ParseRemoteManifestRef parses a provider://owner/repo/path@ref reference,
+// and returns false if the reference is not a remote reference, e.g. it is a
+// local path.
+func ParseRemoteManifestRef(ref string) (RemoteManifestRef, bool, error) {
+	parts := strings.SplitN(ref, "://", 2)
+	if len(parts) != 2 {
+		return RemoteManifestRef{}, false, nil
+	}
+	r := RemoteManifestRef{Provider: parts[0]}
+	spec := parts[1]
+	if i := strings.LastIndex(spec, "@"); i >= 0 {
+		spec, r.Ref = spec[:i], spec[i+1:]
+	}
+	elements := strings.SplitN(strings.Trim(spec, "/"), "/", 3)
+	if r.Provider == "" || len(elements) < 2 || elements[0] == "" || elements[1] == "" {
+		return RemoteManifestRef{}, true, invalidManifestRefError(ref)
+	}
+	r.Repo = elements[0] + "/" + elements[1]
+	if len(elements) == 3 {
+		r.Path = elements[2]
+	}
+	if ext := path.Ext(r.Path); ext != ".yaml" && ext != ".yml" {
+		r.Path = path.Join(r.Path, PipelinesFile)
+	}
+	return r, true, nil
+}
+
+// LoadManifestRef loads a manifest from a local pipelines folder, in the same
+// way as LoadManifest, or from a Git repository if the reference is a
+// provider://owner/repo/path@ref reference.
+//
+// Remote manifests are fetched through the client, the provider must be the
+// driver of the client. When the ref is a commit SHA, the content is cached for
+// the lifetime of the process, so loading the same reference again does not
+// fetch it again, branches and tags can move, so they are always fetched.
+// Remote manifests are validated in the same way as local manifests.
+func LoadManifestRef(ctx context.Context, fs afero.Fs, ref string, client *goscm.Client) (*Manifest, error) {
+	remote, ok, err := ParseRemoteManifestRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return LoadManifest(fs, ref)
+	}
+	if client == nil {
+		return nil, fmt.Errorf("failed to load manifest %s: no Git client", remote)
+	}
+	if driver := client.Driver.String(); driver != remote.Provider {
+		return nil, fmt.Errorf("failed to load manifest %s: the Git client is for %s", remote, driver)
+	}
+	data, err := fetchManifest(ctx, remote, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest %s: %w", remote, err)
+	}
+	m, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest %s: %w", remote, err)
+	}
+	return configureManifest(m)
+}
+
+func fetchManifest(ctx context.Context, ref RemoteManifestRef, client *goscm.Client) ([]byte, error) {
+	key := ref.String()
+	if client.BaseURL != nil {
+		key = client.BaseURL.String() + " " + key
+	}
+	cacheable := isCommitSHA(ref.Ref)
+	if cacheable {
+		if data, ok := remoteManifests.get(key); ok {
+			return data, nil
+		}
+	}
+	content, _, err := client.Contents.Find(ctx, ref.Repo, ref.Path, ref.Ref)
+	if err != nil {
+		return nil, err
+	}
+	if cacheable {
+		remoteManifests.add(key, content.Data)
+	}
+	return content.Data, nil
+}
+
+// commitSHA matches the full SHA-1 or SHA-256 hash of a commit.
+var commitSHA = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// isCommitSHA returns true if the ref is the full hash of a commit, which
+// always refers to the same content, unlike a branch or a tag.
+func isCommitSHA(ref string) bool {
+	return commitSHA.MatchString(ref)
+}
+
+func invalidManifestRefError(ref string) error {
+	return fmt.Errorf("invalid manifest reference %q, it must be a local path, or provider://owner/repo/path@ref", ref)
+}
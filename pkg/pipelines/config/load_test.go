@@ -0,0 +1,117 @@
+package config
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestParseRemoteManifestRef(t *testing.T) {
+	refTests := []struct {
+		ref    string
+		want   RemoteManifestRef
+		remote bool
+		errMsg string
+	}{
+		{"/tmp/gitops", RemoteManifestRef{}, false, ""},
+		{"github://org/gitops", RemoteManifestRef{Provider: "github", Repo: "org/gitops", Path: "pipelines.yaml"}, true, ""},
+		{"github://org/gitops/config@main", RemoteManifestRef{Provider: "github", Repo: "org/gitops", Path: "config/pipelines.yaml", Ref: "main"}, true, ""},
+		{"gitlab://org/gitops/manifest.yaml@v1.0.0", RemoteManifestRef{Provider: "gitlab", Repo: "org/gitops", Path: "manifest.yaml", Ref: "v1.0.0"}, true, ""},
+		{"github://org@main", RemoteManifestRef{}, true, "invalid manifest reference"},
+	}
+
+	for _, tt := range refTests {
+		t.Run(tt.ref, func(rt *testing.T) {
+			got, remote, err := ParseRemoteManifestRef(tt.ref)
+			if !matchErrorString(rt, tt.errMsg, err) {
+				rt.Fatalf("error did not match: got %v, want %q", err, tt.errMsg)
+			}
+			if remote != tt.remote {
+				rt.Fatalf("got remote %v, want %v", remote, tt.remote)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				rt.Fatalf("reference did not match:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadManifestRef(t *testing.T) {
+	client, data := fake.NewDefault()
+	data.ContentDir = "testdata/repos"
+	contents := &recordingContentService{ContentService: client.Contents, refs: map[string]string{}}
+	client.Contents = contents
+
+	for i := 0; i < 2; i++ {
+		m, err := LoadManifestRef(context.Background(), ioutils.NewFilesystem(), "fake://org/manifests@main", client)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if env := m.GetEnvironment("development"); env == nil {
+			t.Fatal("failed to find the development environment in the manifest")
+		}
+	}
+	if ref := contents.refs["pipelines.yaml"]; ref != "main" {
+		t.Fatalf("manifest was fetched at ref %q, want %q", ref, "main")
+	}
+	// The branch can move, so it is fetched each time.
+	if contents.calls != 2 {
+		t.Fatalf("manifest was fetched %d times, want 2", contents.calls)
+	}
+}
+
+func TestLoadManifestRefAtCommit(t *testing.T) {
+	client, data := fake.NewDefault()
+	data.ContentDir = "testdata/repos"
+	contents := &recordingContentService{ContentService: client.Contents, refs: map[string]string{}}
+	client.Contents = contents
+	sha := "9c4f7a1d8e2b3c6f5a0d1e2f3a4b5c6d7e8f9a0b"
+	defer func(c *manifestCache) { remoteManifests = c }(remoteManifests)
+	remoteManifests = &manifestCache{refs: map[string]string{}, contents: map[string][]byte{}}
+
+	for i := 0; i < 2; i++ {
+		if _, err := LoadManifestRef(context.Background(), ioutils.NewFilesystem(), "fake://org/manifests@"+sha, client); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ref := contents.refs["pipelines.yaml"]; ref != sha {
+		t.Fatalf("manifest was fetched at ref %q, want %q", ref, sha)
+	}
+	if contents.calls != 1 {
+		t.Fatalf("manifest was fetched %d times, want 1", contents.calls)
+	}
+}
+
+func TestLoadManifestRefValidatesManifest(t *testing.T) {
+	client, data := fake.NewDefault()
+	data.ContentDir = "testdata/repos"
+
+	_, err := LoadManifestRef(context.Background(), ioutils.NewFilesystem(), "fake://org/manifests/environments", client)
+	if !matchErrorString(t, `missing field\(s\)`, err) {
+		t.Fatalf("got error %v, want a validation error", err)
+	}
+}
+
+func TestLoadManifestRefWithWrongProvider(t *testing.T) {
+	client, _ := fake.NewDefault()
+
+	_, err := LoadManifestRef(context.Background(), ioutils.NewFilesystem(), "github://org/manifests", client)
+	if !matchErrorString(t, "the Git client is for fake", err) {
+		t.Fatalf("got error %v, want a provider error", err)
+	}
+}
+
+func matchErrorString(t *testing.T, s string, e error) bool {
+	t.Helper()
+	if s == "" && e == nil {
+		return true
+	}
+	if s != "" && e == nil {
+		return false
+	}
+	return s != "" && regexp.MustCompile(s).MatchString(e.Error())
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/mkmik/multierror"
+	"knative.dev/pkg/apis"
+)
+
+// Merge returns a manifest with the environments, applications and services
+// of both manifests, neither manifest is modified.
+//
+// Objects with the same name in both manifests are combined, a field that is
+// only set in one of the manifests is taken from it, and a field that is set
+// to different values in each is reported as a conflict. The environments,
+// applications and services are sorted by name, so that the result does not
+// depend on the order of the objects, or of the manifests.
+//
+// The merged manifest is validated, and the errors are returned if it is not
+// valid.
+func Merge(base, overlay *Manifest) (*Manifest, error) {
+	b, o := copyManifest(base), copyManifest(overlay)
+
+	errs := []error{}
+	merged := &Manifest{}
+	mergeFields(reflect.ValueOf(merged).Elem(), reflect.ValueOf(b).Elem(), reflect.ValueOf(o).Elem(), "", &errs)
+	merged.Environments = mergeEnvironments(b.Environments, o.Environments, &errs)
+	sortManifest(merged)
+	if len(errs) > 0 {
+		return nil, multierror.Join(sortErrors(errs))
+	}
+	if err := merged.Validate(); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+func mergeEnvironments(base, overlay []*Environment, errs *[]error) []*Environment {
+	named := map[string]*Environment{}
+	for _, env := range append(base, overlay...) {
+		previous, ok := named[env.Name]
+		if !ok {
+			named[env.Name] = env
+			continue
+		}
+		merged := &Environment{}
+		path := yamlPath(PathForEnvironment(env))
+		mergeFields(reflect.ValueOf(merged).Elem(), reflect.ValueOf(previous).Elem(), reflect.ValueOf(env).Elem(), path, errs)
+		merged.Apps = mergeApplications(previous, previous.Apps, env.Apps, errs)
+		named[env.Name] = merged
+	}
+	envs := []*Environment{}
+	for _, env := range named {
+		envs = append(envs, env)
+	}
+	return envs
+}
+
+func mergeApplications(env *Environment, base, overlay []*Application, errs *[]error) []*Application {
+	named := map[string]*Application{}
+	for _, app := range append(base, overlay...) {
+		previous, ok := named[app.Name]
+		if !ok {
+			named[app.Name] = app
+			continue
+		}
+		merged := &Application{}
+		path := yamlPath(PathForApplication(env, app))
+		mergeFields(reflect.ValueOf(merged).Elem(), reflect.ValueOf(previous).Elem(), reflect.ValueOf(app).Elem(), path, errs)
+		merged.Services = mergeServices(env, app, previous.Services, app.Services, errs)
+		named[app.Name] = merged
+	}
+	apps := []*Application{}
+	for _, app := range named {
+		apps = append(apps, app)
+	}
+	return apps
+}
+
+func mergeServices(env *Environment, app *Application, base, overlay []*Service, errs *[]error) []*Service {
+	named := map[string]*Service{}
+	for _, svc := range append(base, overlay...) {
+		previous, ok := named[svc.Name]
+		if !ok {
+			named[svc.Name] = svc
+			continue
+		}
+		merged := &Service{}
+		path := yamlPath(PathForService(app, env, svc.Name))
+		mergeFields(reflect.ValueOf(merged).Elem(), reflect.ValueOf(previous).Elem(), reflect.ValueOf(svc).Elem(), path, errs)
+		named[svc.Name] = merged
+	}
+	services := []*Service{}
+	for _, svc := range named {
+		services = append(services, svc)
+	}
+	return services
+}
+
+// sortManifest sorts the environments, applications and services by name.
+func sortManifest(m *Manifest) {
+	sort.Sort(byName(m.Environments))
+	for _, env := range m.Environments {
+		sort.Slice(env.Apps, func(i, j int) bool { return env.Apps[i].Name < env.Apps[j].Name })
+		for _, app := range env.Apps {
+			sort.Slice(app.Services, func(i, j int) bool { return app.Services[i].Name < app.Services[j].Name })
+		}
+	}
+}
+
+// mergeFields sets the fields of merged from base and overlay, except for the
+// environments, applications and services, which are merged by name.
+func mergeFields(merged, base, overlay reflect.Value, path string, errs *[]error) {
+	t := merged.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := jsonName(f)
+		if name == "" {
+			continue
+		}
+		switch f.Type {
+		case reflect.TypeOf([]*Environment{}), reflect.TypeOf([]*Application{}), reflect.TypeOf([]*Service{}):
+			continue
+		}
+		b, o := base.Field(i), overlay.Field(i)
+		switch {
+		case o.IsZero() || reflect.DeepEqual(b.Interface(), o.Interface()):
+			merged.Field(i).Set(b)
+		case b.IsZero():
+			merged.Field(i).Set(o)
+		case f.Name == "SourceURL" && canonicalGitURL(b.String()) == canonicalGitURL(o.String()):
+			// Different spellings of the same repository are merged to the
+			// canonical URL, so that the result doesn't depend on the order.
+			merged.Field(i).SetString(canonicalGitURL(b.String()))
+		default:
+			fieldPath := name
+			if path != "" {
+				fieldPath = yamlJoin(path, name)
+			}
+			*errs = append(*errs, mergeConflictError(name, b.Interface(), o.Interface(), []string{fieldPath}))
+		}
+	}
+}
+
+// copyManifest returns a deep copy of the manifest, or an empty manifest if it
+// is nil.
+func copyManifest(m *Manifest) *Manifest {
+	if m == nil {
+		return &Manifest{}
+	}
+	return m.Clone()
+}
+
+func mergeConflictError(field string, base, overlay interface{}, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("conflicting values for %q in the merged manifests", field),
+		Details: fmt.Sprintf("the base manifest has %s, and the overlay has %s", summarizeValue(base), summarizeValue(overlay)),
+		Paths:   paths,
+	}
+}
+
+func summarizeValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mkmik/multierror"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestMerge(t *testing.T) {
+	base := parseManifest(t, "testdata/merge_base.yaml")
+	overlay := parseManifest(t, "testdata/merge_overlay.yaml")
+
+	merged, err := Merge(base, overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Manifest{
+		GitOpsURL: "https://github.com/myproject/gitops.git",
+		Config:    &Config{Pipelines: &PipelinesConfig{Name: "cicd"}},
+		Environments: []*Environment{
+			{
+				Name: "development",
+				Pipelines: &Pipelines{
+					Integration: &TemplateBinding{Template: "dev-ci-template", Bindings: []string{"dev-ci-binding"}},
+				},
+				Apps: []*Application{
+					{
+						Name: "my-app-1",
+						Services: []*Service{
+							{
+								Name:      "service-http",
+								SourceURL: "https://github.com/myproject/service-http",
+								Webhook:   &Webhook{Secret: &Secret{Name: "service-http-secret", Namespace: "cicd"}},
+							},
+							{Name: "service-metrics", SourceURL: "https://github.com/myproject/service-metrics.git"},
+						},
+					},
+					{
+						Name:     "my-app-2",
+						Services: []*Service{{Name: "service-redis", SourceURL: "https://github.com/myproject/service-redis.git"}},
+					},
+				},
+			},
+			{
+				Name: "production",
+				Apps: []*Application{
+					{
+						Name:     "my-app-1",
+						Services: []*Service{{Name: "service-http", SourceURL: "https://github.com/myproject/service-http-production.git"}},
+					},
+				},
+			},
+			{
+				Name: "staging",
+				Apps: []*Application{
+					{
+						Name:     "my-app-1",
+						Services: []*Service{{Name: "service-http", SourceURL: "https://github.com/myproject/service-http-staging.git"}},
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, merged); diff != "" {
+		t.Fatalf("Merge() failed:\n%s", diff)
+	}
+
+	reversed, err := Merge(overlay, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(merged, reversed); diff != "" {
+		t.Fatalf("Merge() depends on the order of the manifests:\n%s", diff)
+	}
+	if diff := cmp.Diff(parseManifest(t, "testdata/merge_base.yaml"), base); diff != "" {
+		t.Fatalf("Merge() modified the base manifest:\n%s", diff)
+	}
+}
+
+func TestMergeWithConflicts(t *testing.T) {
+	base := parseManifest(t, "testdata/merge_base.yaml")
+	overlay := parseManifest(t, "testdata/merge_conflicts.yaml")
+
+	_, err := Merge(base, overlay)
+	want := multierror.Join([]error{
+		mergeConflictError("source_url", "https://github.com/myproject/service-http.git", "https://github.com/myproject/other-service.git",
+			[]string{"environments.development.apps.my-app-1.services.service-http.source_url"}),
+		mergeConflictError("pipelines",
+			&Pipelines{Integration: &TemplateBinding{Template: "dev-ci-template", Bindings: []string{"dev-ci-binding"}}},
+			&Pipelines{Integration: &TemplateBinding{Template: "other-ci-template"}},
+			[]string{"environments.development.pipelines"}),
+		mergeConflictError("gitops_url", "https://github.com/myproject/gitops.git", "https://github.com/myproject/other-gitops.git",
+			[]string{"gitops_url"}),
+	})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMergeWithInvalidResult(t *testing.T) {
+	base := parseManifest(t, "testdata/validate_all_base.yaml")
+	overlay := parseManifest(t, "testdata/merge_overlay.yaml")
+
+	_, err := Merge(base, overlay)
+	want := multierror.Join([]error{
+		duplicateSourceError("https://github.com/myproject/service-http", []string{
+			"environments.development.apps.my-app-1.services.app-1-service-http",
+			"environments.development.apps.my-app-1.services.service-http"}),
+		missingGitOpsURLError([]string{"environments.development.apps.my-app-1.services.service-http.webhook"}),
+	})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func parseManifest(t *testing.T, filename string) *Manifest {
+	t.Helper()
+	m, err := ParseFile(ioutils.NewFilesystem(), filename)
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	return m
+}
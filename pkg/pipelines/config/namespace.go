@@ -0,0 +1,47 @@
+package config
+
+import "fmt"
+
+// NamespaceFor returns the namespace that the resources of the named
+// environment, and of its applications and services, are deployed to.
+//
+// An error is returned if the environment is deployed to the cluster of the
+// pipelines, and the namespace is also the Argo CD or pipelines namespace
+// from the config.
+func (m *Manifest) NamespaceFor(envName string) (string, error) {
+	env := m.GetEnvironment(envName)
+	if env == nil {
+		return "", fmt.Errorf("environment %s does not exist", envName)
+	}
+	namespace := EnvironmentNamespace(env)
+	if configPath, ok := configNamespaces(m.Config)[namespace]; ok && env.Cluster == "" {
+		return "", configNamespaceError(namespace, []string{yamlPath(PathForEnvironment(env)), configPath})
+	}
+	return namespace, nil
+}
+
+// EnvironmentNamespace returns the namespace that the resources of the
+// environment are deployed to, it is the same as Manifest.NamespaceFor, for
+// callers that already have the environment e.g. visitors.
+//
+// The manifest has no separate namespace field, each environment is deployed
+// to the namespace with the same name.
+func EnvironmentNamespace(env *Environment) string {
+	return env.Name
+}
+
+// configNamespaces maps the namespaces that are created for the config, the
+// Argo CD and pipelines namespaces, to their paths in the manifest.
+func configNamespaces(c *Config) map[string]string {
+	namespaces := map[string]string{}
+	if c == nil {
+		return namespaces
+	}
+	if c.ArgoCD != nil && c.ArgoCD.Namespace != "" {
+		namespaces[c.ArgoCD.Namespace] = yamlPath(PathForArgoCD())
+	}
+	if c.Pipelines != nil && c.Pipelines.Name != "" {
+		namespaces[c.Pipelines.Name] = yamlPath(PathForPipelines(c.Pipelines))
+	}
+	return namespaces
+}
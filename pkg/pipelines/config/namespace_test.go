@@ -0,0 +1,57 @@
+package config
+
+import "testing"
+
+func TestNamespaceFor(t *testing.T) {
+	m := &Manifest{
+		Environments: []*Environment{
+			{Name: "development"},
+			{Name: "staging"},
+		},
+	}
+
+	ns, err := m.NamespaceFor("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ns != "staging" {
+		t.Fatalf("NamespaceFor() got %q, want %q", ns, "staging")
+	}
+}
+
+func TestNamespaceForUnknownEnvironment(t *testing.T) {
+	m := &Manifest{Environments: []*Environment{{Name: "development"}}}
+
+	_, err := m.NamespaceFor("production")
+	if msg := "environment production does not exist"; err == nil || err.Error() != msg {
+		t.Fatalf("NamespaceFor() got error %v, want %q", err, msg)
+	}
+}
+
+func TestNamespaceForConfigNamespace(t *testing.T) {
+	m := &Manifest{
+		Config: &Config{
+			ArgoCD:    &ArgoCDConfig{Namespace: "argocd"},
+			Pipelines: &PipelinesConfig{Name: "cicd"},
+		},
+		Environments: []*Environment{
+			{Name: "argocd"},
+			{Name: "cicd", Cluster: "https://api.example.com:6443"},
+		},
+	}
+
+	_, err := m.NamespaceFor("argocd")
+	want := configNamespaceError("argocd", []string{"environments.argocd", "config.argocd"})
+	if err == nil || err.Error() != want.Error() {
+		t.Fatalf("NamespaceFor() got error %v, want %q", err, want)
+	}
+
+	// The environment is deployed to a different cluster to the pipelines.
+	ns, err := m.NamespaceFor("cicd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ns != "cicd" {
+		t.Fatalf("NamespaceFor() got %q, want %q", ns, "cicd")
+	}
+}
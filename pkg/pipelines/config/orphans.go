@@ -0,0 +1,59 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// OrphanedConfigs returns the paths of the parts of the config that are not
+// used by any environment, application or service, so that stale config can
+// be removed from the manifest. These are the TriggerBindings declared in the
+// pipelines config that no pipelines reference, the Argo CD config when there
+// are no applications, and the Git drivers for hosts that none of the
+// repositories are hosted on.
+//
+// The parts that are used are recorded while validating the manifest, but the
+// validation errors are not reported, the config of an invalid manifest can
+// still be orphaned.
+func (m *Manifest) OrphanedConfigs() []string {
+	if m.Config == nil {
+		return []string{}
+	}
+	vv := m.validate()
+	orphans := []string{}
+	if m.Config.Pipelines != nil {
+		for _, name := range m.Config.Pipelines.Bindings {
+			if !vv.usedBindings[name] {
+				orphans = append(orphans, yamlJoin("config", "pipelines", "bindings", name))
+			}
+		}
+	}
+	if m.Config.ArgoCD != nil && vv.applications == 0 {
+		orphans = append(orphans, "config.argocd")
+	}
+	if m.Config.Git != nil && len(m.Config.Git.Drivers) > 0 {
+		hosts := map[string]bool{}
+		if m.GitOpsURL != "" {
+			hosts[gitHost(m.GitOpsURL)] = true
+		}
+		for url := range vv.serviceURLs {
+			hosts[gitHost(url)] = true
+		}
+		for repo := range vv.configRepos {
+			hosts[gitHost(repo)] = true
+		}
+		for host := range m.Config.Git.Drivers {
+			if !hosts[host] {
+				orphans = append(orphans, yamlJoin("config", "git", "drivers", host))
+			}
+		}
+	}
+	sort.Strings(orphans)
+	return orphans
+}
+
+// gitHost returns the host of a Git repository URL, or of a URL normalized by
+// normalizeGitURL.
+func gitHost(rawURL string) string {
+	return strings.SplitN(normalizeGitURL(rawURL), "/", 2)[0]
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestOrphanedConfigs(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/orphaned_configs.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"config.git.drivers.gitlab.example.com",
+		"config.pipelines.bindings.gitlab-push-binding",
+	}
+	if diff := cmp.Diff(want, m.OrphanedConfigs()); diff != "" {
+		t.Fatalf("OrphanedConfigs() failed:\n%s", diff)
+	}
+}
+
+func TestOrphanedConfigsWithoutApplications(t *testing.T) {
+	m := &Manifest{
+		Config: &Config{
+			Pipelines: &PipelinesConfig{Name: "cicd", Bindings: []string{"github-push-binding"}},
+			ArgoCD:    &ArgoCDConfig{Namespace: "argocd"},
+		},
+		Environments: []*Environment{{Name: "development"}},
+	}
+	want := []string{
+		"config.argocd",
+		"config.pipelines.bindings.github-push-binding",
+	}
+	if diff := cmp.Diff(want, m.OrphanedConfigs()); diff != "" {
+		t.Fatalf("OrphanedConfigs() failed:\n%s", diff)
+	}
+}
+
+func TestOrphanedConfigsWithoutConfig(t *testing.T) {
+	m := &Manifest{Environments: []*Environment{{Name: "development"}}}
+	if got := m.OrphanedConfigs(); len(got) != 0 {
+		t.Fatalf("OrphanedConfigs() got %v, want none", got)
+	}
+}
@@ -1,16 +1,26 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"path/filepath"
+	"regexp"
+	"strings"
 
+	"github.com/mkmik/multierror"
 	"github.com/spf13/afero"
+	yamlv2 "gopkg.in/yaml.v2"
+	"knative.dev/pkg/apis"
 	"sigs.k8s.io/yaml"
 )
 
 // Parse decodes YAML describing an environment manifest.
+//
+// Anchors and aliases, including merge keys, are expanded before the manifest
+// is decoded, so each object in the manifest is independent of the others.
 func Parse(in io.Reader) (*Manifest, error) {
 	m := &Manifest{}
 	buf, err := ioutil.ReadAll(in)
@@ -24,6 +34,189 @@ func Parse(in io.Reader) (*Manifest, error) {
 	return m, nil
 }
 
+// ParseAndValidate decodes and validates the manifest in the data, in the same
+// way as LoadManifest, the errors are attributed to the source of the data e.g.
+// the name of the file that it was read from, so that the errors for several
+// manifests can be told apart.
+//
+// The paths of the validation errors are prefixed with the source name and a
+// colon e.g. pipelines.yaml:environments.development, other errors are
+// prefixed with the source name.
+func ParseAndValidate(data []byte, sourceName string) (*Manifest, error) {
+	m, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", sourceName, err)
+	}
+	m, err = configureManifest(m)
+	if err != nil {
+		errs := []error{}
+		for _, err := range multierror.Split(err) {
+			errs = append(errs, viaSource(err, sourceName))
+		}
+		return nil, multierror.Join(errs)
+	}
+	return m, nil
+}
+
+// viaSource returns the error with its paths prefixed with the name of the
+// source of the manifest.
+func viaSource(err error, sourceName string) error {
+	fe, ok := asFieldError(err)
+	if !ok {
+		return fmt.Errorf("%s: %w", sourceName, err)
+	}
+	paths := []string{}
+	for _, path := range fe.Paths {
+		paths = append(paths, sourceName+":"+path)
+	}
+	if len(paths) == 0 {
+		paths = append(paths, sourceName)
+	}
+	return withCategoryOf(err, &apis.FieldError{Message: fe.Message, Details: fe.Details, Paths: paths})
+}
+
+// ParseManifestFrom decodes the manifest that is embedded in a larger YAML
+// document at the path, a dotted key e.g. spec.kam, and validates it. The
+// paths in the validation errors are prefixed with the path, so that the
+// errors can be located in the larger document.
+//
+// An empty path decodes the whole document as the manifest.
+func ParseManifestFrom(in io.Reader, path string) (*Manifest, error) {
+	buf, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := yaml.YAMLToJSON(buf)
+	if err != nil {
+		return nil, err
+	}
+	fragment, err := manifestFragment(doc, path)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(fragment, m); err != nil {
+		return nil, fmt.Errorf("failed to decode the manifest at %q: %w", path, err)
+	}
+	m, err = configureManifest(m)
+	if err != nil {
+		if path == "" {
+			return nil, err
+		}
+		errs := []error{}
+		for _, err := range multierror.Split(err) {
+			errs = append(errs, viaEmbeddingPath(err, path))
+		}
+		return nil, multierror.Join(errs)
+	}
+	return m, nil
+}
+
+// manifestFragment returns the JSON at the dotted path in the JSON document.
+func manifestFragment(doc []byte, path string) ([]byte, error) {
+	if path == "" {
+		return doc, nil
+	}
+	keys := strings.Split(path, ".")
+	for i, key := range keys {
+		fields := map[string]json.RawMessage{}
+		if err := json.Unmarshal(doc, &fields); err != nil {
+			if i == 0 {
+				return nil, fmt.Errorf("failed to find the manifest at %q, the document is not a map", path)
+			}
+			return nil, fmt.Errorf("failed to find the manifest at %q, %q is not a map", path, strings.Join(keys[:i], "."))
+		}
+		value, ok := fields[key]
+		if !ok || string(value) == "null" {
+			return nil, fmt.Errorf("failed to find the manifest at %q, %q is not in the document", path, strings.Join(keys[:i+1], "."))
+		}
+		doc = value
+	}
+	return doc, nil
+}
+
+func viaEmbeddingPath(err error, path string) error {
+	if fe, ok := asFieldError(err); ok {
+		return withCategoryOf(err, fe.ViaField(path))
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
+
+// Marshal encodes the manifest as YAML, the fields are in the same order as
+// the fields of the types, and fields with empty values are omitted, so that
+// changes to the manifest produce minimal diffs.
+//
+// Parsing the YAML returns a manifest equal to this one.
+func (m *Manifest) Marshal() ([]byte, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	// JSON is YAML, decoding into a MapSlice keeps the order of the fields
+	// from the JSON encoding, which is the order of the fields of the types.
+	ordered := yamlv2.MapSlice{}
+	if err := yamlv2.Unmarshal(b, &ordered); err != nil {
+		return nil, err
+	}
+	return yamlv2.Marshal(ordered)
+}
+
+// ParseManifestStrict decodes YAML describing an environment manifest, unlike
+// Parse, it fails if the YAML contains fields that are not part of the
+// manifest, this catches misspelled fields.
+func ParseManifestStrict(in io.Reader) (*Manifest, error) {
+	m := &Manifest{}
+	buf, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	expanded, err := expandAliases(buf)
+	if err != nil {
+		return nil, err
+	}
+	err = yaml.UnmarshalStrict(expanded, m)
+	if err != nil {
+		return nil, unknownFieldError(buf, err)
+	}
+	return m, nil
+}
+
+// expandAliases returns the YAML with the anchors and aliases expanded, as
+// JSON.
+//
+// Strict decoding of YAML reports the keys that override the keys from a merge
+// key as duplicates, so aliases must be expanded first.
+func expandAliases(buf []byte) ([]byte, error) {
+	return yaml.YAMLToJSON(buf)
+}
+
+var unknownFieldRE = regexp.MustCompile(`unknown field "([^"]+)"`)
+
+// unknownFieldError replaces the error from decoding an unknown field with one
+// that identifies the line the field was found on.
+//
+// The decoding error does not have the line, so the line is only reported if
+// the field is a key on a single line of the YAML, otherwise the field can't
+// be told apart from the other uses of the same key.
+func unknownFieldError(buf []byte, err error) error {
+	match := unknownFieldRE.FindStringSubmatch(err.Error())
+	if match == nil {
+		return err
+	}
+	field := match[1]
+	lines := []int{}
+	for i, line := range strings.Split(string(buf), "\n") {
+		key := strings.TrimPrefix(strings.TrimSpace(line), "- ")
+		if strings.HasPrefix(key, field+":") {
+			lines = append(lines, i+1)
+		}
+	}
+	if len(lines) == 1 {
+		return fmt.Errorf("unknown field %q at line %d", field, lines[0])
+	}
+	return fmt.Errorf("unknown field %q", field)
+}
+
 // ParseFile is a wrapper around Parse that accepts a filename, it opens and
 // parses the file, and closes it.
 func ParseFile(fs afero.Fs, filename string) (*Manifest, error) {
@@ -35,9 +228,30 @@ func ParseFile(fs afero.Fs, filename string) (*Manifest, error) {
 	return Parse(f)
 }
 
+// ParseFileStrict is a wrapper around ParseManifestStrict that accepts a
+// filename, it opens and parses the file, and closes it.
+func ParseFileStrict(fs afero.Fs, filename string) (*Manifest, error) {
+	f, err := fs.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseManifestStrict(f)
+}
+
 // ParsePipelinesFolder will accept the pipelines folder path
 // and appends pipelines file name before parsing it
 func ParsePipelinesFolder(fs afero.Fs, folderPath string) (*Manifest, error) {
+	return parsePipelinesFolder(fs, folderPath, ParseFile)
+}
+
+// ParsePipelinesFolderStrict is the same as ParsePipelinesFolder but parses
+// the file with ParseFileStrict.
+func ParsePipelinesFolderStrict(fs afero.Fs, folderPath string) (*Manifest, error) {
+	return parsePipelinesFolder(fs, folderPath, ParseFileStrict)
+}
+
+func parsePipelinesFolder(fs afero.Fs, folderPath string, parse func(afero.Fs, string) (*Manifest, error)) (*Manifest, error) {
 	info, err := fs.Stat(folderPath)
 	if err != nil {
 		return nil, err
@@ -45,5 +259,5 @@ func ParsePipelinesFolder(fs afero.Fs, folderPath string) (*Manifest, error) {
 	if !info.IsDir() {
 		return nil, fmt.Errorf("the path %q is a file path (required directory path)", folderPath)
 	}
-	return ParseFile(fs, filepath.Join(folderPath, PipelinesFile))
+	return parse(fs, filepath.Join(folderPath, PipelinesFile))
 }
@@ -1,10 +1,14 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"math/rand"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/mkmik/multierror"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
 )
@@ -105,6 +109,63 @@ var parseTests = []struct {
 		},
 	},
 	},
+	{"testdata/anchors.yaml", &Manifest{
+		Config: &Config{
+			Pipelines: &PipelinesConfig{Name: "cicd"},
+		},
+		Environments: []*Environment{
+			{
+				Name: "development",
+				Pipelines: &Pipelines{
+					Integration: &TemplateBinding{
+						Template: "dev-ci-template",
+						Bindings: []string{"github-push-binding", "dev-ci-binding"},
+					},
+				},
+				Apps: []*Application{
+					{
+						Name: "my-app-1",
+						Services: []*Service{
+							{
+								Name: "service-1",
+								Pipelines: &Pipelines{
+									Integration: &TemplateBinding{
+										Template: "service-ci-template",
+										Bindings: []string{"github-push-binding", "dev-ci-binding"},
+									},
+								},
+							},
+							{
+								Name: "service-2",
+								Pipelines: &Pipelines{
+									Integration: &TemplateBinding{
+										Template: "service-ci-template",
+										Bindings: []string{"github-push-binding", "dev-ci-binding"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				Name: "staging",
+				Pipelines: &Pipelines{
+					Integration: &TemplateBinding{
+						Template: "dev-ci-template",
+						Bindings: []string{"github-push-binding", "dev-ci-binding"},
+					},
+				},
+				Apps: []*Application{
+					{
+						Name:     "my-app-1",
+						Services: []*Service{{Name: "service-1"}},
+					},
+				},
+			},
+		},
+	},
+	},
 	{"testdata/example-with-cluster.yaml", &Manifest{
 		Environments: []*Environment{
 			{
@@ -175,3 +236,305 @@ func TestParsePipelinesFolder(t *testing.T) {
 		t.Fatalf("ParsePipelinesFolder() failed: %s", diff)
 	}
 }
+
+func TestParseManifestStrict(t *testing.T) {
+	for _, tt := range parseTests {
+		// example2.yaml has an unknown "cicd" field.
+		if tt.filename == "testdata/example2.yaml" {
+			continue
+		}
+		t.Run(fmt.Sprintf("parsing %s", tt.filename), func(rt *testing.T) {
+			fs := ioutils.NewFilesystem()
+			got, err := ParseFileStrict(fs, tt.filename)
+			if err != nil {
+				rt.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				rt.Errorf("ParseFileStrict(%s) failed diff\n%s", tt.filename, diff)
+			}
+		})
+	}
+}
+
+func TestParseWithAliases(t *testing.T) {
+	m, err := ParseFileStrict(ioutils.NewFilesystem(), "testdata/anchors.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The services reference the same bindings, but changing one does not
+	// change the other.
+	services := m.Environments[0].Apps[0].Services
+	services[0].Pipelines.Integration.Bindings[0] = "changed-binding"
+	want := []string{"github-push-binding", "dev-ci-binding"}
+	if diff := cmp.Diff(want, services[1].Pipelines.Integration.Bindings); diff != "" {
+		t.Fatalf("aliased bindings changed:\n%s", diff)
+	}
+
+	fakeFs := ioutils.NewMemoryFilesystem()
+	if err := yaml.MarshalItemToFile(fakeFs, "pipelines.yaml", m); err != nil {
+		t.Fatal(err)
+	}
+	rendered, err := ParseFileStrict(fakeFs, "pipelines.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(m, rendered); diff != "" {
+		t.Fatalf("rendered manifest did not match:\n%s", diff)
+	}
+}
+
+func TestParseManifestStrictWithUnknownField(t *testing.T) {
+	fs := ioutils.NewFilesystem()
+	_, err := ParseFileStrict(fs, "testdata/unknown_field.yaml")
+
+	want := `unknown field "servcies" at line 5`
+	if err == nil || err.Error() != want {
+		t.Fatalf("ParseFileStrict() got error %v, want %q", err, want)
+	}
+
+	m, err := ParseFile(fs, "testdata/unknown_field.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := m.Environments[0].Apps[0].Services; s != nil {
+		t.Fatalf("ParseFile() got services %#v, want nil", s)
+	}
+}
+
+func TestParseManifestStrictWithRepeatedUnknownField(t *testing.T) {
+	_, err := ParseFileStrict(ioutils.NewFilesystem(), "testdata/unknown_field_repeated.yaml")
+
+	want := `unknown field "servcies"`
+	if err == nil || err.Error() != want {
+		t.Fatalf("ParseFileStrict() got error %v, want %q", err, want)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	m := &Manifest{
+		GitOpsURL: "https://github.com/example/gitops.git",
+		Environments: []*Environment{
+			{
+				Name: "development",
+				Apps: []*Application{
+					{Name: "app-1", Services: []*Service{{Name: "service-1", SourceURL: "https://github.com/example/service-1.git"}}},
+				},
+			},
+		},
+		Config: &Config{Pipelines: &PipelinesConfig{Name: "cicd"}},
+	}
+
+	b, err := m.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `gitops_url: https://github.com/example/gitops.git
+environments:
+- name: development
+  apps:
+  - name: app-1
+    services:
+    - name: service-1
+      source_url: https://github.com/example/service-1.git
+config:
+  pipelines:
+    name: cicd
+`
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Fatalf("Marshal() failed:\n%s", diff)
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	for _, tt := range parseTests {
+		t.Run(fmt.Sprintf("round-trip %s", tt.filename), func(rt *testing.T) {
+			assertRoundTrip(rt, tt.want)
+		})
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		assertRoundTrip(t, randomManifest(r))
+	}
+}
+
+func assertRoundTrip(t *testing.T, m *Manifest) {
+	t.Helper()
+	b, err := m.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("failed to parse the marshaled manifest: %v\n%s", err, b)
+	}
+	if diff := cmp.Diff(m, parsed); diff != "" {
+		t.Fatalf("round-trip failed:\n%s\n%s", diff, b)
+	}
+}
+
+// randomManifest returns a manifest with randomly chosen fields set, empty
+// slices are nil, as they are omitted when the manifest is marshaled.
+func randomManifest(r *rand.Rand) *Manifest {
+	m := &Manifest{GitOpsURL: randomString(r), Version: r.Intn(3)}
+	for i := r.Intn(3); i > 0; i-- {
+		env := &Environment{Name: randomString(r), Cluster: randomString(r), Pipelines: randomPipelines(r)}
+		for j := r.Intn(3); j > 0; j-- {
+			app := &Application{Name: randomString(r)}
+			if r.Intn(2) == 0 {
+				app.ConfigRepo = &Repository{URL: randomString(r), TargetRevision: randomString(r), Path: randomString(r)}
+			}
+			for k := r.Intn(3); k > 0; k-- {
+				svc := &Service{
+					Name:                randomString(r),
+					SourceURL:           randomString(r),
+					Pipelines:           randomPipelines(r),
+					AllowForeignGitType: r.Intn(2) == 0,
+					ImageRepo:           randomString(r),
+				}
+				if r.Intn(2) == 0 {
+					svc.Webhook = &Webhook{Secret: &Secret{Name: randomString(r), Namespace: randomString(r), Key: randomString(r)}}
+				}
+				app.Services = append(app.Services, svc)
+			}
+			env.Apps = append(env.Apps, app)
+		}
+		m.Environments = append(m.Environments, env)
+	}
+	if r.Intn(2) == 0 {
+		m.Config = &Config{
+			NamePolicy:            randomString(r),
+			SkipGitTypeValidation: r.Intn(2) == 0,
+		}
+		if r.Intn(2) == 0 {
+			m.Config.Pipelines = &PipelinesConfig{Name: randomString(r), Bindings: randomStrings(r)}
+		}
+		if r.Intn(2) == 0 {
+			m.Config.ArgoCD = &ArgoCDConfig{Namespace: randomString(r)}
+		}
+		if r.Intn(2) == 0 {
+			m.Config.Git = &GitConfig{Drivers: map[string]string{randomString(r) + "host": randomString(r)}}
+		}
+		if p := randomPipelines(r); p != nil {
+			m.Config.Defaults = &Defaults{Pipelines: p}
+		}
+	}
+	return m
+}
+
+func randomPipelines(r *rand.Rand) *Pipelines {
+	if r.Intn(2) == 0 {
+		return nil
+	}
+	return &Pipelines{Integration: &TemplateBinding{Template: randomString(r) + "template", Bindings: randomStrings(r)}}
+}
+
+func randomStrings(r *rand.Rand) []string {
+	var s []string
+	for i := r.Intn(3); i > 0; i-- {
+		s = append(s, randomString(r)+"value")
+	}
+	return s
+}
+
+// randomString returns one of a set of strings, including empty strings and
+// strings that need quoting in YAML.
+func randomString(r *rand.Rand) string {
+	values := []string{"", "service-1", "https://github.com/example/repo.git", "true", "123", "a: b", "#comment", "- item", "yes", "null", "~"}
+	return values[r.Intn(len(values))]
+}
+
+func TestParseManifestFrom(t *testing.T) {
+	f, err := ioutils.NewFilesystem().Open("testdata/embedded_manifest.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	_, err = ParseManifestFrom(f, "spec.kam")
+
+	want := multierror.Join([]error{
+		invalidNameError("service_1", DNS1035Error, []string{"spec.kam.environments.development.apps.app-1.services.service_1"}),
+	})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseManifestFromWithValidManifest(t *testing.T) {
+	doc := `
+spec:
+  kam:
+    environments:
+      - name: development
+`
+	m, err := ParseManifestFrom(strings.NewReader(doc), "spec.kam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Manifest{Environments: []*Environment{{Name: "development"}}}
+	if diff := cmp.Diff(want, m); diff != "" {
+		t.Fatalf("ParseManifestFrom() failed:\n%s", diff)
+	}
+}
+
+func TestParseManifestFromWithMissingPath(t *testing.T) {
+	pathTests := []struct {
+		path    string
+		wantErr string
+	}{
+		{"spec.kam", `failed to find the manifest at "spec.kam", "spec.kam" is not in the document`},
+		{"spec.owner.kam", `failed to find the manifest at "spec.owner.kam", "spec.owner" is not a map`},
+		{"status", `failed to find the manifest at "status", "status" is not in the document`},
+	}
+
+	for _, tt := range pathTests {
+		t.Run(tt.path, func(rt *testing.T) {
+			_, err := ParseManifestFrom(strings.NewReader("spec:\n  owner: team-1\n"), tt.path)
+			if err == nil || err.Error() != tt.wantErr {
+				rt.Fatalf("ParseManifestFrom() got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseAndValidate(t *testing.T) {
+	data := []byte(`
+environments:
+  - name: development
+    apps:
+      - name: app_1
+        config_repo:
+          url: https://github.com/org/config.git
+          path: config
+`)
+	_, err := ParseAndValidate(data, "team-a/pipelines.yaml")
+
+	want := multierror.Join([]error{
+		invalidNameError("app_1", DNS1035Error, []string{"team-a/pipelines.yaml:environments.development.apps.app_1"}),
+	})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseAndValidateWithValidManifest(t *testing.T) {
+	m, err := ParseAndValidate([]byte("environments:\n  - name: development\n"), "pipelines.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Manifest{Environments: []*Environment{{Name: "development"}}}
+	if diff := cmp.Diff(want, m); diff != "" {
+		t.Fatalf("ParseAndValidate() failed:\n%s", diff)
+	}
+}
+
+func TestParseAndValidateWithInvalidYAML(t *testing.T) {
+	_, err := ParseAndValidate([]byte("environments: ["), "pipelines.yaml")
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to parse manifest pipelines.yaml: ") {
+		t.Fatalf("ParseAndValidate() got error %v, want a parse error for pipelines.yaml", err)
+	}
+}
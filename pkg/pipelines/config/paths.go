@@ -0,0 +1,196 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The names of the files that are generated by building the manifest, the
+// builders use these, so that ResourcePaths stays in step with them.
+const (
+	// KustomizationFile is the name of the generated kustomization files.
+	KustomizationFile = "kustomization.yaml"
+	// EventListenerPath is the path of the EventListener within the base of
+	// the CICD environment.
+	EventListenerPath = "08-eventlisteners/cicd-event-listener.yaml"
+)
+
+// EnvironmentNamespaceFile returns the name of the file for the namespace of
+// the environment.
+func EnvironmentNamespaceFile(env *Environment) string {
+	return env.Name + "-environment.yaml"
+}
+
+// EnvironmentRoleBindingFile returns the name of the file for the RoleBinding
+// that lets the pipelines deploy to the environment.
+func EnvironmentRoleBindingFile(env *Environment) string {
+	return env.Name + "-rolebinding.yaml"
+}
+
+// PathForArgoCDApplication returns the path of the file for the Argo CD
+// application with the name.
+func PathForArgoCDApplication(name string) string {
+	return filepath.Join(PathForArgoCD(), name+".yaml")
+}
+
+// PathForArgoCDEnvironmentApplication returns the path of the file for the
+// Argo CD application with the name, that is generated for an environment, or
+// an application within it.
+func PathForArgoCDEnvironmentApplication(name string) string {
+	return PathForArgoCDApplication(name + "-app")
+}
+
+// ResourcePaths returns the sorted paths of the files that are generated by
+// building the manifest, without touching the filesystem.
+func (m *Manifest) ResourcePaths() ([]string, error) {
+	return PathContext{}.ResourcePaths(m)
+}
+
+// PathContext generates the paths of the objects in the manifest within a base
+// directory of the repository e.g. gitops, rather than at the root of the
+// repository.
+//
+// The paths in validation errors are always relative to the root, regardless
+// of the base, Rel converts the generated paths back to these.
+type PathContext struct {
+	// Base is the directory that the paths are generated in, if it is empty
+	// the paths are the same as from PathForEnvironment etc.
+	Base string
+	// Names derives the names of the generated resources, which some of the
+	// paths are named after.
+	Names Names
+}
+
+// PathForService returns the path of the service within the base directory.
+func (pc PathContext) PathForService(app *Application, env *Environment, serviceName string) string {
+	return pc.join(PathForService(app, env, serviceName))
+}
+
+// PathForApplication returns the path of the application within the base
+// directory.
+func (pc PathContext) PathForApplication(env *Environment, app *Application) string {
+	return pc.join(PathForApplication(env, app))
+}
+
+// PathForEnvironment returns the path of the environment within the base
+// directory.
+func (pc PathContext) PathForEnvironment(env *Environment) string {
+	return pc.join(PathForEnvironment(env))
+}
+
+// PathForPipelines returns the path of the CICD environment within the base
+// directory.
+func (pc PathContext) PathForPipelines(pipeline *PipelinesConfig) string {
+	return pc.join(PathForPipelines(pipeline))
+}
+
+// PathForArgoCD returns the path of the ArgoCD configuration within the base
+// directory.
+func (pc PathContext) PathForArgoCD() string {
+	return pc.join(PathForArgoCD())
+}
+
+// ResourcePaths returns the sorted paths of the files that are generated by
+// building the manifest within the base directory.
+func (pc PathContext) ResourcePaths(m *Manifest) ([]string, error) {
+	pv := &pathsVisitor{
+		paths:     map[string]bool{},
+		pipelines: m.GetPipelinesConfig(),
+		argoCD:    m.GetArgoCDConfig() != nil && m.GitOpsURL != "",
+		names:     pc.Names,
+	}
+	if err := m.Walk(pv); err != nil {
+		return nil, err
+	}
+	if m.GitOpsURL != "" && pv.pipelines != nil {
+		pv.add(filepath.Join(PathForPipelines(pv.pipelines), "base", EventListenerPath))
+	}
+	if pv.argoCD && m.Config.ArgoCD.Namespace != "" {
+		pv.add(PathForArgoCDApplication(ArgoCDConfigAppName))
+		if pv.pipelines != nil {
+			pv.add(PathForArgoCDApplication(ArgoCDCICDAppName))
+		}
+		pv.add(filepath.Join(PathForArgoCD(), KustomizationFile))
+	}
+
+	paths := []string{}
+	for k := range pv.paths {
+		paths = append(paths, pc.join(k))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Rel returns the repo-rooted path for a path within the base directory, or an
+// error if the path is outside of the base directory.
+func (pc PathContext) Rel(path string) (string, error) {
+	if pc.Base == "" {
+		return filepath.Clean(path), nil
+	}
+	rel, err := filepath.Rel(filepath.Clean(pc.Base), filepath.Clean(path))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %q is not within the base directory %q", path, pc.Base)
+	}
+	return rel, nil
+}
+
+func (pc PathContext) join(path string) string {
+	return filepath.Join(pc.Base, path)
+}
+
+type pathsVisitor struct {
+	paths     map[string]bool
+	pipelines *PipelinesConfig
+	argoCD    bool
+	names     Names
+}
+
+func (pv *pathsVisitor) Environment(env *Environment) error {
+	envPath := filepath.Join(PathForEnvironment(env), "env")
+	pv.add(filepath.Join(envPath, "base", EnvironmentNamespaceFile(env)),
+		filepath.Join(envPath, "base", KustomizationFile),
+		filepath.Join(envPath, "overlays", KustomizationFile))
+	if pv.pipelines != nil && hasServices(env) {
+		pv.add(filepath.Join(envPath, "base", EnvironmentRoleBindingFile(env)))
+	}
+	if pv.argoCD {
+		pv.add(PathForArgoCDEnvironmentApplication(pv.names.ArgoCDEnvironmentName(env.Name)))
+	}
+	return nil
+}
+
+func (pv *pathsVisitor) Application(env *Environment, app *Application) error {
+	appPath := PathForApplication(env, app)
+	pv.add(filepath.Join(appPath, KustomizationFile),
+		filepath.Join(appPath, "base", KustomizationFile),
+		filepath.Join(appPath, "overlays", KustomizationFile))
+	if pv.argoCD {
+		pv.add(PathForArgoCDEnvironmentApplication(pv.names.ArgoCDApplicationName(env.Name, app.Name)))
+	}
+	return nil
+}
+
+func (pv *pathsVisitor) Service(app *Application, env *Environment, svc *Service) error {
+	svcPath := PathForService(app, env, svc.Name)
+	pv.add(filepath.Join(svcPath, KustomizationFile),
+		filepath.Join(svcPath, "base", KustomizationFile),
+		filepath.Join(svcPath, "overlays", KustomizationFile))
+	return nil
+}
+
+func (pv *pathsVisitor) add(paths ...string) {
+	for _, p := range paths {
+		pv.paths[p] = true
+	}
+}
+
+func hasServices(env *Environment) bool {
+	for _, app := range env.Apps {
+		if len(app.Services) > 0 {
+			return true
+		}
+	}
+	return false
+}
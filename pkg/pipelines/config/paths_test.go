@@ -0,0 +1,96 @@
+package config
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestResourcePaths(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/resource_paths.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := ioutil.ReadFile("testdata/resource_paths.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := m.ResourcePaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Split(strings.TrimSpace(string(golden)), "\n")
+	if diff := cmp.Diff(want, paths); diff != "" {
+		t.Fatalf("ResourcePaths() failed:\n%s", diff)
+	}
+}
+
+func TestPathContext(t *testing.T) {
+	env := &Environment{Name: "development"}
+	app := &Application{Name: "app-1"}
+	pipelines := &PipelinesConfig{Name: "cicd"}
+
+	pathTests := []struct {
+		base string
+		got  string
+		want string
+	}{
+		{"", PathContext{}.PathForService(app, env, "service-1"), "environments/development/apps/app-1/services/service-1"},
+		{"gitops", PathContext{Base: "gitops"}.PathForService(app, env, "service-1"), "gitops/environments/development/apps/app-1/services/service-1"},
+		{"gitops", PathContext{Base: "gitops"}.PathForApplication(env, app), "gitops/environments/development/apps/app-1"},
+		{"gitops/", PathContext{Base: "gitops/"}.PathForEnvironment(env), "gitops/environments/development"},
+		{"gitops", PathContext{Base: "gitops"}.PathForPipelines(pipelines), "gitops/config/cicd"},
+		{"gitops", PathContext{Base: "gitops"}.PathForArgoCD(), "gitops/config/argocd"},
+	}
+
+	for _, tt := range pathTests {
+		if tt.got != tt.want {
+			t.Errorf("base %q: got %q, want %q", tt.base, tt.got, tt.want)
+		}
+	}
+}
+
+func TestPathContextRel(t *testing.T) {
+	pc := PathContext{Base: "gitops"}
+	env := &Environment{Name: "development"}
+
+	rel, err := pc.Rel(pc.PathForEnvironment(env))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := PathForEnvironment(env); rel != want {
+		t.Fatalf("got %q, want %q", rel, want)
+	}
+	if _, err := pc.Rel("config/argocd"); err == nil {
+		t.Fatal("expected an error for a path outside of the base directory")
+	}
+}
+
+func TestPathContextResourcePaths(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/resource_paths.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths, err := m.ResourcePaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	based, err := PathContext{Base: "gitops"}.ResourcePaths(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{}
+	for _, p := range paths {
+		want = append(want, "gitops/"+p)
+	}
+	if diff := cmp.Diff(want, based); diff != "" {
+		t.Fatalf("ResourcePaths() failed:\n%s", diff)
+	}
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mkmik/multierror"
+	"knative.dev/pkg/apis"
+)
+
+// RewriteURLs replaces each of the Git repository URLs in the manifest, the
+// gitops_url, the config_repo url of each application, and the source_url of
+// each service, with the URL returned by rewrite e.g. when migrating the
+// repositories to a different host, and then validates the manifest.
+//
+// Empty URLs are not rewritten. The URLs are rewritten in a copy of the
+// manifest, which replaces the manifest only if all of the URLs are rewritten
+// and the rewritten manifest is valid, otherwise the manifest is unchanged,
+// and the errors are returned.
+func (m *Manifest) RewriteURLs(rewrite func(old string) (string, error)) error {
+	rewritten := m.Clone()
+	rv := &urlRewriter{rewrite: rewrite}
+	rv.rewriteURL(&rewritten.GitOpsURL, "gitops_url")
+	// The visitor does not return errors, and the limits are checked when
+	// the manifest is validated.
+	_ = rewritten.walk(context.Background(), rv, Limits{}, neverStop)
+	if len(rv.errs) > 0 {
+		return multierror.Join(rv.errs)
+	}
+	if err := rewritten.Validate(); err != nil {
+		return err
+	}
+	*m = *rewritten
+	return nil
+}
+
+// urlRewriter rewrites the Git repository URLs of the applications and
+// services that it visits.
+type urlRewriter struct {
+	rewrite func(string) (string, error)
+	errs    []error
+}
+
+func (rv *urlRewriter) Application(env *Environment, app *Application) error {
+	if app.ConfigRepo != nil {
+		rv.rewriteURL(&app.ConfigRepo.URL, yamlJoin(yamlPath(PathForApplication(env, app)), "config_repo", "url"))
+	}
+	return nil
+}
+
+func (rv *urlRewriter) Service(app *Application, env *Environment, svc *Service) error {
+	rv.rewriteURL(&svc.SourceURL, yamlJoin(yamlPath(PathForService(app, env, svc.Name)), "source_url"))
+	return nil
+}
+
+func (rv *urlRewriter) rewriteURL(url *string, path string) {
+	if *url == "" {
+		return
+	}
+	rewritten, err := rv.rewrite(*url)
+	if err != nil {
+		rv.errs = append(rv.errs, rewriteURLError(*url, err, []string{path}))
+		return
+	}
+	*url = rewritten
+}
+
+func rewriteURLError(url string, err error, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("failed to rewrite the URL %q", url),
+		Details: err.Error(),
+		Paths:   paths,
+	}
+}
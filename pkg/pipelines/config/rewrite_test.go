@@ -0,0 +1,103 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mkmik/multierror"
+)
+
+func TestRewriteURLs(t *testing.T) {
+	m := rewriteTestManifest()
+
+	err := m.RewriteURLs(func(old string) (string, error) {
+		return strings.Replace(old, "github.com", "gitlab.com", 1), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := rewriteTestManifest()
+	want.GitOpsURL = "https://gitlab.com/org/gitops.git"
+	want.Environments[0].Apps[0].Services[0].SourceURL = "https://gitlab.com/org/service-1.git"
+	want.Environments[1].Apps[0].ConfigRepo.URL = "https://gitlab.com/org/config.git"
+	if diff := cmp.Diff(want, m); diff != "" {
+		t.Fatalf("RewriteURLs() failed:\n%s", diff)
+	}
+}
+
+func TestRewriteURLsWithErrors(t *testing.T) {
+	m := rewriteTestManifest()
+	failed := errors.New("no mapping for the repository")
+
+	err := m.RewriteURLs(func(old string) (string, error) {
+		if strings.HasSuffix(old, "gitops.git") {
+			return strings.Replace(old, "github.com", "gitlab.com", 1), nil
+		}
+		return "", failed
+	})
+
+	want := multierror.Join([]error{
+		rewriteURLError("https://github.com/org/service-1.git", failed, []string{"environments.development.apps.app-1.services.service-1.source_url"}),
+		rewriteURLError("https://github.com/org/config.git", failed, []string{"environments.staging.apps.app-2.config_repo.url"}),
+	})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+	// None of the URLs are rewritten, including the gitops_url.
+	if diff := cmp.Diff(rewriteTestManifest(), m); diff != "" {
+		t.Fatalf("RewriteURLs() changed the manifest:\n%s", diff)
+	}
+}
+
+func TestRewriteURLsValidatesTheManifest(t *testing.T) {
+	m := rewriteTestManifest()
+
+	err := m.RewriteURLs(func(old string) (string, error) {
+		// The config repository becomes the GitOps repository.
+		return "https://github.com/org/gitops.git", nil
+	})
+
+	want := multierror.Join([]error{
+		gitOpsConfigRepoError("github.com/org/gitops", []string{"environments.staging.apps.app-2.config_repo.url"}),
+	})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(rewriteTestManifest(), m); diff != "" {
+		t.Fatalf("RewriteURLs() changed the manifest:\n%s", diff)
+	}
+}
+
+func rewriteTestManifest() *Manifest {
+	return &Manifest{
+		GitOpsURL: "https://github.com/org/gitops.git",
+		Environments: []*Environment{
+			{
+				Name: "development",
+				Pipelines: &Pipelines{
+					Integration: &TemplateBinding{Template: "dev-ci-template", Bindings: []string{"github-push-binding"}},
+				},
+				Apps: []*Application{
+					{
+						Name: "app-1",
+						Services: []*Service{
+							{Name: "service-1", SourceURL: "https://github.com/org/service-1.git"},
+						},
+					},
+				},
+			},
+			{
+				Name: "staging",
+				Apps: []*Application{
+					{
+						Name:       "app-2",
+						ConfigRepo: &Repository{URL: "https://github.com/org/config.git", Path: "config"},
+					},
+				},
+			},
+		},
+	}
+}
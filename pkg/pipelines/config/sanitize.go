@@ -0,0 +1,55 @@
+package config
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+// NameSanitizer derives the names of the generated resources e.g. the
+// EventListener triggers, from the names in the manifest.
+type NameSanitizer interface {
+	// Sanitize returns the name made from the parts, e.g. a prefix and the
+	// environment, application and service names, the name should be no
+	// longer than the limit.
+	Sanitize(limit int, parts ...string) string
+}
+
+// WithNameSanitizer validates the names of the generated resources as they are
+// derived by the sanitizer, the resources must be generated with the same
+// sanitizer for the validation to check the names that are generated.
+func WithNameSanitizer(s NameSanitizer) ValidateOption {
+	return func(vv *validateVisitor) {
+		vv.names = Names{Sanitizer: s}
+	}
+}
+
+// Names derives the names of the generated resources from the names in the
+// manifest with the Sanitizer, the zero value derives them with the
+// HashSanitizer.
+type Names struct {
+	Sanitizer NameSanitizer
+}
+
+func (n Names) sanitize(limit int, parts ...string) string {
+	if n.Sanitizer == nil {
+		return HashSanitizer{}.Sanitize(limit, parts...)
+	}
+	return n.Sanitizer.Sanitize(limit, parts...)
+}
+
+// HashSanitizer joins the parts of names with "-", and truncates names that
+// exceed the limit, with a hash of the full name appended, so that different
+// names are unlikely to be truncated to the same name.
+type HashSanitizer struct{}
+
+// Sanitize implements the NameSanitizer interface.
+func (HashSanitizer) Sanitize(limit int, parts ...string) string {
+	name := strings.Join(parts, "-")
+	if len(name) <= limit {
+		return name
+	}
+	sum := fmt.Sprintf("%x", sha256.Sum256([]byte(name)))[:serviceNameHashLength]
+	prefix := strings.TrimRight(name[:limit-serviceNameHashLength-1], "-")
+	return prefix + "-" + sum
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mkmik/multierror"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+)
+
+// joinSanitizer joins the parts of names without shortening them.
+type joinSanitizer struct{}
+
+func (joinSanitizer) Sanitize(limit int, parts ...string) string {
+	return strings.Join(parts, "-")
+}
+
+func TestHashSanitizer(t *testing.T) {
+	sanitizeTests := []struct {
+		limit int
+		parts []string
+		want  string
+	}{
+		{10, []string{"my", "app"}, "my-app"},
+		{10, []string{"my-service"}, "my-service"},
+		{10, []string{"my-long-service"}, "m-bc05827a"},
+		{16, []string{"a-long", "service"}, "a-long-service"},
+	}
+
+	for _, tt := range sanitizeTests {
+		got := HashSanitizer{}.Sanitize(tt.limit, tt.parts...)
+		if got != tt.want {
+			t.Errorf("Sanitize(%d, %q) got %q, want %q", tt.limit, tt.parts, got, tt.want)
+		}
+	}
+}
+
+func TestValidateWithNameSanitizer(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/truncated_trigger_names.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	err = m.Validate(WithNameSanitizer(joinSanitizer{}))
+
+	trigger := "app-ci-build-from-push-service-with-a-long-name-for-the-triggers"
+	want := invalidGeneratedNameError(trigger, "trigger", k8svalidation.MaxLenError(triggerNameLimit),
+		[]string{"environments.development.apps.my-application.services.service-with-a-long-name-for-the-triggers"})
+	if err := matchMultiErrors(t, err, multierror.Join([]error{want})); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNamesWithSanitizer(t *testing.T) {
+	names := Names{Sanitizer: joinSanitizer{}}
+
+	svc := "service-with-a-long-name-for-the-triggers"
+	if got, want := names.TriggerName("development", "my-app", svc), "app-ci-build-from-push-"+svc; got != want {
+		t.Errorf("TriggerName(%q) got %q, want %q", svc, got, want)
+	}
+	if got, want := names.ArgoCDApplicationName("development", "my-app"), "development-my-app"; got != want {
+		t.Errorf("ArgoCDApplicationName() got %q, want %q", got, want)
+	}
+	if got, want := names.ArgoCDEnvironmentName("development"), "development-env"; got != want {
+		t.Errorf("ArgoCDEnvironmentName() got %q, want %q", got, want)
+	}
+	// The zero value derives the names with the HashSanitizer.
+	if got, want := (Names{}).TriggerName("development", "my-app", svc), TriggerName("development", "my-app", svc); got != want {
+		t.Errorf("TriggerName(%q) got %q, want %q", svc, got, want)
+	}
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+const schemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// schemaConstraints are the constraints enforced by the validator that can be
+// expressed in the schema, keyed by the type they apply to.
+var schemaConstraints = map[reflect.Type]schemaConstraint{
+	reflect.TypeOf(Environment{}):     {required: []string{"name"}},
+	reflect.TypeOf(Application{}):     {required: []string{"name"}, oneOf: []string{"services", "config_repo"}},
+	reflect.TypeOf(Service{}):         {required: []string{"name"}},
+	reflect.TypeOf(Webhook{}):         {required: []string{"secret"}},
+	reflect.TypeOf(Secret{}):          {required: []string{"name", "namespace"}},
+	reflect.TypeOf(Repository{}):      {required: []string{"url", "path"}},
+	reflect.TypeOf(Pipelines{}):       {required: []string{"integration"}},
+	reflect.TypeOf(PipelinesConfig{}): {required: []string{"name"}},
+	reflect.TypeOf(ArgoCDConfig{}):    {required: []string{"namespace"}},
+	reflect.TypeOf(Config{}): {
+		enums: map[string][]string{"name_policy": {NamePolicyDNS1035, NamePolicyDNS1123}},
+	},
+}
+
+type schemaConstraint struct {
+	required []string
+	// oneOf are the fields of which exactly one must be provided.
+	oneOf []string
+	enums map[string][]string
+}
+
+// ManifestJSONSchema returns a JSON Schema describing the structure of the
+// manifest, generated from the Manifest type.
+//
+// The schema includes the required fields, and the fields that are mutually
+// exclusive, but the other checks made by Validate are not included.
+func ManifestJSONSchema() ([]byte, error) {
+	g := &schemaGenerator{definitions: map[string]interface{}{}}
+	schema := g.schemaFor(reflect.TypeOf(Manifest{}))
+	schema["$schema"] = schemaDraft
+	schema["title"] = "KAM pipelines manifest"
+	schema["definitions"] = g.definitions
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// schemaGenerator generates schemas for types, each struct type is added to
+// the definitions once, and referenced where it is used.
+type schemaGenerator struct {
+	definitions map[string]interface{}
+}
+
+func (g *schemaGenerator) schemaFor(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := jsonName(f)
+		if name == "" {
+			continue
+		}
+		properties[name] = g.schemaForField(f.Type)
+	}
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	c := schemaConstraints[t]
+	if len(c.required) > 0 {
+		schema["required"] = c.required
+	}
+	if len(c.oneOf) > 0 {
+		oneOf := []interface{}{}
+		for _, field := range c.oneOf {
+			oneOf = append(oneOf, map[string]interface{}{"required": []string{field}})
+		}
+		schema["oneOf"] = oneOf
+	}
+	for field, values := range c.enums {
+		properties[field].(map[string]interface{})["enum"] = values
+	}
+	return schema
+}
+
+func (g *schemaGenerator) schemaForField(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schemaForField(t.Elem())
+	case reflect.Struct:
+		if _, ok := g.definitions[t.Name()]; !ok {
+			// The placeholder stops recursive types from being generated
+			// again.
+			g.definitions[t.Name()] = nil
+			g.definitions[t.Name()] = g.schemaFor(t)
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + t.Name()}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": g.schemaForField(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schemaForField(t.Elem())}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	}
+	return map[string]interface{}{"type": "string"}
+}
+
+// jsonName returns the name of the field in the JSON encoding, or an empty
+// string if the field is not encoded.
+func jsonName(f reflect.StructField) string {
+	if f.PkgPath != "" {
+		return ""
+	}
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	}
+	return name
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestManifestJSONSchema(t *testing.T) {
+	golden, err := ioutil.ReadFile("testdata/manifest_schema.golden.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema, err := ManifestJSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(string(golden), string(schema)+"\n"); diff != "" {
+		t.Fatalf("ManifestJSONSchema() failed:\n%s", diff)
+	}
+}
+
+func TestManifestJSONSchemaApplicationConstraints(t *testing.T) {
+	b, err := ManifestJSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		Definitions map[string]struct {
+			Required []string                 `json:"required"`
+			OneOf    []map[string]interface{} `json:"oneOf"`
+		} `json:"definitions"`
+	}
+	if err := json.Unmarshal(b, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	app := schema.Definitions["Application"]
+	if diff := cmp.Diff([]string{"name"}, app.Required); diff != "" {
+		t.Fatalf("required fields did not match:\n%s", diff)
+	}
+	want := []map[string]interface{}{
+		{"required": []interface{}{"services"}},
+		{"required": []interface{}{"config_repo"}},
+	}
+	if diff := cmp.Diff(want, app.OneOf); diff != "" {
+		t.Fatalf("oneOf did not match:\n%s", diff)
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+// Stats are the counts of the objects in a manifest.
+type Stats struct {
+	Environments int `json:"environments"`
+	Applications int `json:"applications"`
+	Services     int `json:"services"`
+	// ServicesWithWebhooks is the number of services with a webhook.
+	ServicesWithWebhooks int `json:"services_with_webhooks"`
+	// SourceRepositories is the number of distinct service source URLs, they
+	// are canonicalized in the same way as Manifest.SourceRepositories.
+	SourceRepositories int `json:"source_repositories"`
+	// ConfigRepoApplications is the number of applications with a
+	// config_repo.
+	ConfigRepoApplications int `json:"config_repo_applications"`
+}
+
+// StatsVisitor counts the objects in a manifest as it is walked.
+type StatsVisitor struct {
+	stats   Stats
+	sources map[string]bool
+}
+
+// NewStatsVisitor creates and returns a new StatsVisitor.
+func NewStatsVisitor() *StatsVisitor {
+	return &StatsVisitor{sources: map[string]bool{}}
+}
+
+// Stats returns the counts of the objects that have been visited.
+func (sv *StatsVisitor) Stats() Stats {
+	return sv.stats
+}
+
+// Environment implements the EnvironmentVisitor interface.
+func (sv *StatsVisitor) Environment(env *Environment) error {
+	sv.stats.Environments++
+	return nil
+}
+
+// Application implements the ApplicationVisitor interface.
+func (sv *StatsVisitor) Application(env *Environment, app *Application) error {
+	sv.stats.Applications++
+	if app.ConfigRepo != nil {
+		sv.stats.ConfigRepoApplications++
+	}
+	return nil
+}
+
+// Service implements the ServiceVisitor interface.
+func (sv *StatsVisitor) Service(app *Application, env *Environment, svc *Service) error {
+	sv.stats.Services++
+	if svc.Webhook != nil {
+		sv.stats.ServicesWithWebhooks++
+	}
+	if svc.SourceURL != "" {
+		url := canonicalGitURL(svc.SourceURL)
+		if !sv.sources[url] {
+			sv.sources[url] = true
+			sv.stats.SourceRepositories++
+		}
+	}
+	return nil
+}
+
+// Stats returns the counts of the objects in the manifest.
+func (m *Manifest) Stats() Stats {
+	sv := NewStatsVisitor()
+	// The visitor does not return errors.
+	_ = m.Walk(sv)
+	return sv.Stats()
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestStats(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/stats.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+
+	want := Stats{
+		Environments:           3,
+		Applications:           3,
+		Services:               4,
+		ServicesWithWebhooks:   1,
+		SourceRepositories:     2,
+		ConfigRepoApplications: 1,
+	}
+	if diff := cmp.Diff(want, m.Stats()); diff != "" {
+		t.Fatalf("stats did not match:\n%s", diff)
+	}
+}
+
+func TestStatsJSON(t *testing.T) {
+	b, err := json.Marshal(Stats{Environments: 2, Applications: 3, Services: 4, SourceRepositories: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"environments":2,"applications":3,"services":4,"services_with_webhooks":0,"source_repositories":1,"config_repo_applications":0}`
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Fatalf("JSON did not match:\n%s", diff)
+	}
+}
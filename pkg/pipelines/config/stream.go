@@ -0,0 +1,319 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mkmik/multierror"
+	"sigs.k8s.io/yaml"
+)
+
+// ValidateStream decodes and validates the manifest in the reader one
+// environment at a time, so that the whole manifest is never held in memory,
+// the errors are the same as from parsing the manifest and validating it.
+//
+// The visitor, if not nil, is walked over each environment after it has been
+// validated, in the same way as Manifest.Walk, except that the environments
+// are visited in the order they are read, and the errors that it returns are
+// reported with the validation errors.
+//
+// The environments after the limit on the number of environments are read,
+// but not validated.
+//
+// The checks between environments e.g. for duplicate source URLs, are made
+// with the names and URLs that are recorded as the environments are
+// validated. When the config follows the environments in the manifest, the
+// environments are kept in a temporary file until the config has been read.
+//
+// Each environment is decoded separately, until the first YAML anchor is read,
+// the environments from there on are kept in memory, and decoded with the rest
+// of the manifest once it has been read, so that aliases can refer to anchors
+// in other environments, or in the other fields. The environments are
+// validated one at a time, regardless of WithConcurrency.
+func ValidateStream(r io.Reader, visitor interface{}, opts ...ValidateOption) error {
+	vv := newValidateVisitor()
+	for _, o := range opts {
+		o(vv)
+	}
+	sv := &streamValidator{vv: vv, visitor: visitor}
+	defer sv.close()
+
+	header, anchored, err := splitManifest(r, sv.item)
+	if err != nil {
+		return err
+	}
+	if anchored != nil {
+		header = append(append(header, "environments:\n"...), anchored...)
+	}
+	m := &Manifest{}
+	if err := yaml.Unmarshal(header, m); err != nil {
+		return err
+	}
+	if sv.config == nil {
+		if err := sv.start(m); err != nil {
+			return err
+		}
+	}
+	// Environments written in flow style, and the environments after an
+	// anchor, are part of the other fields.
+	for _, env := range m.Environments {
+		sv.validate(env)
+	}
+	if err := checkLimit("environments", sv.environments, vv.limits.Environments, "environments"); err != nil {
+		vv.errs = append(vv.errs, err)
+		return vv.err()
+	}
+	vv.validateAcrossEnvironments(m)
+	return vv.err()
+}
+
+// streamValidator validates the environments of a manifest as they are read by
+// splitManifest.
+type streamValidator struct {
+	vv      *validateVisitor
+	visitor interface{}
+	// config is the manifest with the config that the environments are
+	// validated with, it is nil until the validation has started.
+	config *Manifest
+	// spool records the environments that were read before the config.
+	spool *os.File
+	// spooled are the size and line of each environment in the spool.
+	spooled []spooledItem
+	// environments is the number of environments that have been read, once
+	// it exceeds the limit, the rest of the environments are not validated.
+	environments int
+}
+
+type spooledItem struct {
+	size, line int
+}
+
+// item validates the environment, or records it in the spool if the
+// environments can't be validated until the rest of the manifest is read.
+func (sv *streamValidator) item(header, item []byte, line int) error {
+	if sv.config == nil && sv.spool == nil {
+		m := &Manifest{}
+		if err := yaml.Unmarshal(header, m); err != nil {
+			return err
+		}
+		if m.Config != nil {
+			if err := sv.start(m); err != nil {
+				return err
+			}
+		} else {
+			f, err := ioutil.TempFile("", "manifest-")
+			if err != nil {
+				return err
+			}
+			sv.spool = f
+		}
+	}
+	if sv.config == nil {
+		if _, err := sv.spool.Write(item); err != nil {
+			return err
+		}
+		sv.spooled = append(sv.spooled, spooledItem{size: len(item), line: line})
+		return nil
+	}
+	env, err := decodeEnvironment(item, line)
+	if err != nil {
+		return err
+	}
+	sv.validate(env)
+	return nil
+}
+
+// start validates the top-level fields other than the environments, and
+// then the environments in the spool.
+func (sv *streamValidator) start(m *Manifest) error {
+	sv.config = &Manifest{Config: m.Config}
+	sv.vv.errs = append(sv.vv.errs, sv.vv.validatePolicy()...)
+	sv.vv.errs = append(sv.vv.errs, sv.vv.validateConfig(m)...)
+	if sv.spool == nil {
+		return nil
+	}
+	if _, err := sv.spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	for _, s := range sv.spooled {
+		item := make([]byte, s.size)
+		if _, err := io.ReadFull(sv.spool, item); err != nil {
+			return err
+		}
+		env, err := decodeEnvironment(item, s.line)
+		if err != nil {
+			return err
+		}
+		sv.validate(env)
+	}
+	return nil
+}
+
+// validate validates the environment, and walks the validators and the
+// visitor over it.
+func (sv *streamValidator) validate(env *Environment) {
+	if env == nil {
+		return
+	}
+	sv.environments++
+	if limit := sv.vv.limits.Environments; limit > 0 && sv.environments > limit {
+		return
+	}
+	m := &Manifest{Config: sv.config.Config, Environments: []*Environment{env}}
+	if err := sv.vv.walk(m, sv.vv); err != nil {
+		sv.vv.errs = append(sv.vv.errs, err)
+	}
+	visitors := []interface{}{}
+	for _, v := range sv.vv.extraValidators {
+		visitors = append(visitors, v)
+	}
+	if sv.visitor != nil {
+		visitors = append(visitors, sv.visitor)
+	}
+	for _, v := range visitors {
+		if err := sv.vv.walk(m, v); err != nil {
+			sv.vv.errs = append(sv.vv.errs, multierror.Split(err)...)
+		}
+	}
+}
+
+func (sv *streamValidator) close() {
+	if sv.spool != nil {
+		sv.spool.Close()
+		os.Remove(sv.spool.Name())
+	}
+}
+
+func decodeEnvironment(item []byte, line int) (*Environment, error) {
+	envs := []*Environment{}
+	if err := yaml.Unmarshal(item, &envs); err != nil {
+		return nil, fmt.Errorf("failed to decode the environment at line %d: %w", line, err)
+	}
+	if len(envs) == 0 {
+		return nil, nil
+	}
+	return envs[0], nil
+}
+
+// splitManifest reads the manifest a line at a time, and calls item with the
+// YAML for each element of the environments, and the YAML for the other
+// top-level fields that preceded it. The YAML for all of the top-level fields
+// other than the environments is returned.
+//
+// Environments written in flow style e.g. environments: [] are returned with
+// the other fields.
+//
+// Aliases can refer to any anchor that precedes them, so once a line has an
+// anchor, the elements of the environments are not passed to item, and the
+// YAML for them is returned as the elements of a sequence, or nil if there are
+// no anchors in the environments.
+func splitManifest(r io.Reader, item func(header, env []byte, line int) error) (header, anchored []byte, err error) {
+	br := bufio.NewReader(r)
+	headerBuf := &bytes.Buffer{}
+	current := &bytes.Buffer{}
+	anchoredBuf := &bytes.Buffer{}
+	inEnvironments, hasAnchors := false, false
+	// indent is the indentation of the environment items, once known.
+	indent := -1
+	lineNo, start := 0, 0
+	flush := func() error {
+		if current.Len() == 0 {
+			return nil
+		}
+		if hasAnchors {
+			anchoredBuf.Write(current.Bytes())
+			current.Reset()
+			return nil
+		}
+		err := item(headerBuf.Bytes(), current.Bytes(), start)
+		current.Reset()
+		return err
+	}
+
+	for {
+		line, readErr := br.ReadString('\n')
+		if readErr != nil && readErr != io.EOF {
+			return nil, nil, readErr
+		}
+		if line != "" {
+			lineNo++
+			trimmed := strings.TrimLeft(line, " ")
+			blank := strings.TrimSpace(line) == "" || strings.HasPrefix(trimmed, "#")
+			topLevel := !blank && len(trimmed) == len(line) && !strings.HasPrefix(line, "-")
+			switch {
+			case topLevel:
+				if err := flush(); err != nil {
+					return nil, nil, err
+				}
+				inEnvironments = isEnvironmentsKey(line)
+				indent = -1
+				if !inEnvironments {
+					headerBuf.WriteString(line)
+				}
+			case !inEnvironments:
+				headerBuf.WriteString(line)
+			case blank:
+				// Blank lines and comments before the first environment are
+				// dropped.
+				if current.Len() > 0 {
+					current.WriteString(line)
+				}
+			default:
+				lineIndent := len(line) - len(trimmed)
+				if indent < 0 {
+					indent = lineIndent
+				}
+				if current.Len() == 0 || (lineIndent == indent && isSequenceItem(trimmed)) {
+					if err := flush(); err != nil {
+						return nil, nil, err
+					}
+					start = lineNo
+				}
+				current.WriteString(line)
+			}
+			if !blank && hasAnchor(line) {
+				hasAnchors = true
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, nil, err
+	}
+	if anchoredBuf.Len() > 0 {
+		anchored = anchoredBuf.Bytes()
+	}
+	return headerBuf.Bytes(), anchored, nil
+}
+
+// isEnvironmentsKey returns true if the line starts a block of environments.
+func isEnvironmentsKey(line string) bool {
+	i := strings.Index(line, ":")
+	if i < 0 || strings.Trim(line[:i], `"'`) != "environments" {
+		return false
+	}
+	rest := strings.TrimSpace(line[i+1:])
+	return rest == "" || strings.HasPrefix(rest, "#")
+}
+
+// anchorPattern matches a YAML anchor e.g. &integration, it also matches an &
+// at the start of a word in a string, which only means that the environments
+// are decoded together.
+var anchorPattern = regexp.MustCompile(`(^|[\s\[{,])&[^\s\[\]{},]`)
+
+// hasAnchor returns true if the line could define an anchor.
+func hasAnchor(line string) bool {
+	return anchorPattern.MatchString(line)
+}
+
+func isSequenceItem(s string) bool {
+	return strings.TrimRight(s, "\r\n") == "-" || strings.HasPrefix(s, "- ")
+}
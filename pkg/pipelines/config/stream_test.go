@@ -0,0 +1,172 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"sigs.k8s.io/yaml"
+)
+
+func TestValidateStream(t *testing.T) {
+	for _, tt := range validateTests {
+		t.Run(fmt.Sprintf("%s (%s)", tt.desc, tt.filename), func(rt *testing.T) {
+			f, err := os.Open(tt.filename)
+			if err != nil {
+				rt.Fatal(err)
+			}
+			defer f.Close()
+
+			if err := matchMultiErrors(rt, ValidateStream(f, nil), tt.wantErr); err != nil {
+				rt.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestValidateStreamWithConfigAfterEnvironments(t *testing.T) {
+	for _, tt := range validateTests {
+		t.Run(fmt.Sprintf("%s (%s)", tt.desc, tt.filename), func(rt *testing.T) {
+			m, err := ParseFile(ioutils.NewFilesystem(), tt.filename)
+			if err != nil {
+				rt.Fatalf("failed to parse file:%v", err)
+			}
+			// Marshal writes the environments before the config.
+			b, err := m.Marshal()
+			if err != nil {
+				rt.Fatal(err)
+			}
+
+			if err := matchMultiErrors(rt, ValidateStream(bytes.NewReader(b), nil), tt.wantErr); err != nil {
+				rt.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestValidateStreamWithFlowEnvironments(t *testing.T) {
+	manifest := `gitops_url: https://github.com/example/gitops.git
+environments: [{name: development, apps: [{name: app_1, services: [{name: service-1}]}]}]
+`
+	err := ValidateStream(strings.NewReader(manifest), nil)
+	want := invalidNameError("app_1", DNS1035Error, []string{"environments.development.apps.app_1"})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateStreamWithVisitor(t *testing.T) {
+	f, err := os.Open("testdata/example1.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	visitor := &countingVisitor{}
+
+	if err := ValidateStream(f, visitor); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/example1.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &countingVisitor{}
+	if err := m.Walk(want); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, visitor, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Fatalf("ValidateStream() visited:\n%s", diff)
+	}
+}
+
+func TestValidateStreamWithAnchors(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/anchors.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := m.Validate()
+	f, err := os.Open("testdata/anchors.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	visitor := &countingVisitor{}
+
+	err = ValidateStream(f, visitor)
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+	wantVisited := &countingVisitor{}
+	if err := m.Walk(wantVisited); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(wantVisited, visitor, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Fatalf("ValidateStream() visited:\n%s", diff)
+	}
+}
+
+func TestValidateStreamWithAnchorInLaterEnvironment(t *testing.T) {
+	manifest := `environments:
+- name: development
+  apps:
+  - name: app_1
+- name: staging
+  pipelines:
+    integration: &integration
+      template: stage-ci-template
+- name: production
+  pipelines:
+    integration: *integration
+config:
+  pipelines:
+    name: cicd
+`
+	m := &Manifest{}
+	if err := yaml.Unmarshal([]byte(manifest), m); err != nil {
+		t.Fatal(err)
+	}
+
+	err := ValidateStream(strings.NewReader(manifest), nil)
+	if err := matchMultiErrors(t, err, m.Validate()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateStreamWithInvalidEnvironment(t *testing.T) {
+	manifest := `environments:
+- name: development
+- name: staging
+  apps: [
+`
+	err := ValidateStream(strings.NewReader(manifest), nil)
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to decode the environment at line 3:") {
+		t.Fatalf("ValidateStream() got error %v", err)
+	}
+}
+
+type countingVisitor struct {
+	Environments []string
+	Applications []string
+	Services     []string
+}
+
+func (v *countingVisitor) Environment(env *Environment) error {
+	v.Environments = append(v.Environments, env.Name)
+	return nil
+}
+
+func (v *countingVisitor) Application(env *Environment, app *Application) error {
+	v.Applications = append(v.Applications, env.Name+"/"+app.Name)
+	return nil
+}
+
+func (v *countingVisitor) Service(app *Application, env *Environment, svc *Service) error {
+	v.Services = append(v.Services, env.Name+"/"+app.Name+"/"+svc.Name)
+	return nil
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultTreeWidth is the width that RenderTree fits the lines of the tree
+// to, the width of a typical terminal.
+const DefaultTreeWidth = 80
+
+// RenderTree returns an indented tree of the environments, applications and
+// services in the manifest, for display in a terminal.
+//
+// The environments are in the order that Walk visits them, and the
+// applications and services are sorted by name. Applications with a
+// config_repo, and services with a webhook, are marked.
+//
+//	development
+//	├── app-1
+//	│   ├── service-1 [webhook]
+//	│   └── service-2
+//	└── app-2 [config_repo]
+//	staging
+func RenderTree(m *Manifest) string {
+	return RenderTreeWidth(m, DefaultTreeWidth)
+}
+
+// RenderTreeWidth is the same as RenderTree, but lines longer than the width
+// are truncated, if the width is zero, the lines are not truncated.
+func RenderTreeWidth(m *Manifest, width int) string {
+	tv := &treeVisitor{}
+	// The visitor does not return errors.
+	_ = m.Walk(tv)
+	var sb strings.Builder
+	for _, env := range tv.envs {
+		writeTreeLine(&sb, "", env.label, width)
+		writeTreeChildren(&sb, "", env.children, width)
+	}
+	return sb.String()
+}
+
+type treeNode struct {
+	label    string
+	children []treeNode
+}
+
+// treeVisitor builds the tree as the manifest is walked, the services of an
+// application are visited before the application, and the applications of an
+// environment before the environment.
+type treeVisitor struct {
+	services []treeNode
+	apps     []treeNode
+	envs     []treeNode
+}
+
+func (tv *treeVisitor) Service(app *Application, env *Environment, svc *Service) error {
+	label := svc.Name
+	if svc.Webhook != nil {
+		label += " [webhook]"
+	}
+	tv.services = append(tv.services, treeNode{label: label})
+	return nil
+}
+
+func (tv *treeVisitor) Application(env *Environment, app *Application) error {
+	label := app.Name
+	if app.ConfigRepo != nil {
+		label += " [config_repo]"
+	}
+	tv.apps = append(tv.apps, treeNode{label: label, children: sortTreeNodes(tv.services)})
+	tv.services = nil
+	return nil
+}
+
+func (tv *treeVisitor) Environment(env *Environment) error {
+	tv.envs = append(tv.envs, treeNode{label: env.Name, children: sortTreeNodes(tv.apps)})
+	tv.apps = nil
+	return nil
+}
+
+func sortTreeNodes(nodes []treeNode) []treeNode {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].label < nodes[j].label
+	})
+	return nodes
+}
+
+func writeTreeChildren(sb *strings.Builder, prefix string, children []treeNode, width int) {
+	for i, child := range children {
+		branch, indent := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		writeTreeLine(sb, prefix+branch, child.label, width)
+		writeTreeChildren(sb, prefix+indent, child.children, width)
+	}
+}
+
+// writeTreeLine writes the line, truncated to the width with an ellipsis.
+func writeTreeLine(sb *strings.Builder, prefix, label string, width int) {
+	line := prefix + label
+	if width > 0 && utf8.RuneCountInString(line) > width {
+		line = string([]rune(line)[:width-1]) + "…"
+	}
+	sb.WriteString(line + "\n")
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestRenderTree(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/example1.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	m.Environments[0].Apps[0].Services[0].Webhook = &Webhook{}
+
+	want := `development
+├── my-app-1
+│   └── service-http [webhook]
+└── my-app-2
+    └── service-redis
+production
+└── my-app-1
+    ├── service-http
+    └── service-metrics
+staging
+└── my-app-1 [config_repo]
+`
+	if diff := cmp.Diff(want, RenderTree(m)); diff != "" {
+		t.Fatalf("RenderTree() failed:\n%s", diff)
+	}
+}
+
+func TestRenderTreeWidth(t *testing.T) {
+	m := &Manifest{
+		Environments: []*Environment{
+			{Name: "development", Apps: []*Application{{Name: "an-application-with-a-long-name", Services: []*Service{{Name: "service-1"}}}}},
+		},
+	}
+
+	want := `development
+└── an-application-…
+    └── service-1
+`
+	if diff := cmp.Diff(want, RenderTreeWidth(m, 20)); diff != "" {
+		t.Fatalf("RenderTreeWidth() failed:\n%s", diff)
+	}
+}
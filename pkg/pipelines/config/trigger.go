@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	triggerNamePrefix = "app-ci-build-from-push"
+	// triggerNameLimit is the longest trigger name that is a valid Kubernetes
+	// name.
+	triggerNameLimit = k8svalidation.DNS1123LabelMaxLength
+)
+
+// TriggerName returns the name of the EventListener trigger that is generated
+// for the service in the application and environment.
+//
+// The name is derived with the HashSanitizer, which truncates names that would
+// exceed the Kubernetes name limit, with a hash of the full name appended, so
+// that different services are unlikely to be given the same trigger name.
+//
+// The generated triggers are named after the service only, so the environment
+// and application do not change the name, they are parameters so that the
+// name can include them without changing the callers.
+func TriggerName(env, app, svc string) string {
+	return Names{}.TriggerName(env, app, svc)
+}
+
+// TriggerName returns the name of the EventListener trigger that is generated
+// for the service in the application and environment, derived with the
+// Sanitizer.
+func (n Names) TriggerName(env, app, svc string) string {
+	return n.sanitize(triggerNameLimit, triggerNamePrefix, svc)
+}
+
+// ServiceImageBindingName returns the name of the TriggerBinding that is
+// generated with the image repository of the service, the pipelines of the
+// service can reference it without it being declared.
+func ServiceImageBindingName(env, app, svc string) string {
+	return fmt.Sprintf("%s-%s-%s-binding", env, app, svc)
+}
+
+// triggerNameTruncated returns true if the trigger name that is generated for
+// the service is not the prefix and the service name, e.g. because the
+// sanitizer truncated it.
+func triggerNameTruncated(svc, trigger string) bool {
+	return trigger != triggerNamePrefix+"-"+svc
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestTriggerName(t *testing.T) {
+	nameTests := []struct {
+		svc  string
+		want string
+	}{
+		{"service-http", "app-ci-build-from-push-service-http"},
+		{"service-with-a-long-name-for-the-trigger", "app-ci-build-from-push-service-with-a-long-name-for-the-trigger"},
+		{"service-with-a-long-name-for-the-triggers", "app-ci-build-from-push-service-with-a-long-name-for-th-4d5ef23b"},
+		{"service-with-a-long-name-for-the-triggerz", "app-ci-build-from-push-service-with-a-long-name-for-th-de694f82"},
+	}
+
+	for _, tt := range nameTests {
+		got := TriggerName("development", "my-application", tt.svc)
+		if got != tt.want {
+			t.Errorf("TriggerName(%q) got %q, want %q", tt.svc, got, tt.want)
+		}
+		if len(got) > triggerNameLimit {
+			t.Errorf("TriggerName(%q) got %q, which exceeds %d characters", tt.svc, got, triggerNameLimit)
+		}
+		// The triggers are named after the service only.
+		if staging := TriggerName("staging", "another-application", tt.svc); staging != got {
+			t.Errorf("TriggerName(%q) in staging got %q, want %q", tt.svc, staging, got)
+		}
+	}
+}
+
+func TestValidateWithTruncatedTriggerNames(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/truncated_trigger_names.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	err, warnings := m.ValidateWithWarnings()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`environments.development.apps.my-application.services.service-with-a-long-name-for-the-triggers: trigger name for service "service-with-a-long-name-for-the-triggers" exceeds 63 characters, it is truncated to "` +
+			TriggerName("development", "my-application", "service-with-a-long-name-for-the-triggers") + `"`,
+	}
+	if diff := cmp.Diff(want, warnings); diff != "" {
+		t.Fatalf("warnings did not match:\n%s", diff)
+	}
+}
+
+// shortSanitizer joins the parts of names, and cuts them to 33 characters.
+type shortSanitizer struct{}
+
+func (shortSanitizer) Sanitize(limit int, parts ...string) string {
+	name := strings.Join(parts, "-")
+	if len(name) > 33 {
+		name = strings.TrimRight(name[:33], "-")
+	}
+	return name
+}
+
+func TestValidateWithSanitizedTriggerNames(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/truncated_trigger_names.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	err, warnings := m.ValidateWithWarnings(WithNameSanitizer(shortSanitizer{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`environments.development.apps.my-application.services.service-http: trigger name for service "service-http" is changed to "app-ci-build-from-push-service-ht" by the name sanitizer`,
+		`environments.development.apps.my-application.services.service-with-a-long-name-for-the-triggers: trigger name for service "service-with-a-long-name-for-the-triggers" exceeds 63 characters, it is truncated to "app-ci-build-from-push-service-wi"`,
+	}
+	if diff := cmp.Diff(want, warnings); diff != "" {
+		t.Fatalf("warnings did not match:\n%s", diff)
+	}
+}
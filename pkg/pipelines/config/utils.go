@@ -10,10 +10,26 @@ import (
 // LoadManifest reads a manifest file, and configures the environment based on
 // the configuration.
 func LoadManifest(fs afero.Fs, path string) (*Manifest, error) {
-	m, err := ParsePipelinesFolder(fs, path)
+	return loadManifest(fs, path, ParsePipelinesFolder)
+}
+
+// LoadManifestStrict is the same as LoadManifest, but fails if the manifest
+// contains unknown fields.
+func LoadManifestStrict(fs afero.Fs, path string) (*Manifest, error) {
+	return loadManifest(fs, path, ParsePipelinesFolderStrict)
+}
+
+func loadManifest(fs afero.Fs, path string, parse func(afero.Fs, string) (*Manifest, error)) (*Manifest, error) {
+	m, err := parse(fs, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load manifest: %w", err)
 	}
+	return configureManifest(m)
+}
+
+// configureManifest registers the Git drivers from the manifest, and returns
+// the manifest if it is valid.
+func configureManifest(m *Manifest) (*Manifest, error) {
 	if !(m.Config == nil || m.Config.Git == nil || m.Config.Git.Drivers == nil) {
 		drivers := []factory.MappingFunc{}
 		for k, v := range m.Config.Git.Drivers {
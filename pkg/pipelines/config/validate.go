@@ -1,110 +1,902 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/url"
+	gopath "path"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/mkmik/multierror"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 	"k8s.io/apimachinery/pkg/api/validation"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 )
 
 const (
-	longServiceName  = "a service name cannot exceed 47 characters"
-	serviceNameLimit = 47
+	longServiceName = "a service name cannot exceed 47 characters"
+	// configNameCollision is the detail of the error for application and
+	// service names that are the same as a config name.
+	configNameCollision = "the name cannot be the same as a config name, the resources would overlap"
+	serviceNameLimit    = 47
+	// serviceNameWarningMargin is how close to serviceNameLimit a service name
+	// can get before a warning is generated.
+	serviceNameWarningMargin = 5
+	// serviceNameHashLength is the length of the hash appended to truncated
+	// service names.
+	serviceNameHashLength = 8
+
+	gitURLDetails          = "the URL must be an HTTPS or SSH Git repository URL with a host"
+	clusterURLDetails      = "the cluster must be the HTTP(S) URL of an API server"
+	imageRepoFormatDetails = "expected an image repository in the form <registry>/<namespace>/<name>, or <project>/<app> for the internal registry"
+)
+
+// InternalImageRegistry is the registry for image repositories that only have
+// a project and application e.g. project/app.
+const InternalImageRegistry = "image-registry.openshift-image-registry.svc:5000"
+
+var (
+	imageRegistryRE = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9.-]*[a-zA-Z0-9])?(:[0-9]+)?$`)
+	imagePathRE     = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*$`)
+	envVarNameRE    = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
 )
 
+// DefaultReservedNamespaces are the namespaces that environments cannot use,
+// unless they are replaced with WithReservedNamespaces.
+var DefaultReservedNamespaces = []string{"default", "kube-*"}
+
+// DefaultReservedPipelinesNames are the names that the pipelines config cannot
+// use, as the namespace and resources that are created for the pipelines would
+// conflict with the system resources, unless they are replaced with
+// WithReservedPipelinesNames.
+var DefaultReservedPipelinesNames = []string{"default", "kube-*", "openshift", "openshift-*", "tekton-pipelines"}
+
 type validateVisitor struct {
-	errs         []error
-	envNames     map[string]bool
-	appNames     map[string]bool
-	serviceNames map[string]bool
-	serviceURLs  map[string][]string
-	configNames  map[string]bool
+	walkState
+	// nameFunc validates names according to the manifest's name policy.
+	nameFunc validation.ValidateNameFunc
+	// envNamePattern, appNamePattern and serviceNamePattern are the naming
+	// conventions that names must follow, if they are not nil.
+	envNamePattern     *NamePattern
+	appNamePattern     *NamePattern
+	serviceNamePattern *NamePattern
+	// indices records the position of each object in the manifest, when
+	// errors are reported with indexed paths.
+	indices     *manifestIndices
+	configNames map[string]bool
+	// configNamespaces maps the namespaces created for the config to their
+	// paths.
+	configNamespaces map[string]string
+	// reservedNamespaces are the path.Match patterns for the namespaces that
+	// environments cannot use.
+	reservedNamespaces []string
+	// reservedPipelinesNames are the path.Match patterns for the names that
+	// the pipelines config cannot use.
+	reservedPipelinesNames []string
+	// imageRegistries are the registries that services can push images to,
+	// if this is nil, any registry can be used.
+	imageRegistries map[string]bool
+	// policy configures the severity of the validation rules.
+	policy ValidationPolicy
+	// pipelinesNamespace is the namespace of the pipelines, from the config.
+	pipelinesNamespace string
+	// declaredBindings are the TriggerBindings declared in the pipelines
+	// config, if this is nil, binding references are not checked.
+	declaredBindings map[string]bool
+	// extraValidators are called with the manifest after the core validation.
+	extraValidators []ManifestValidator
+	// limits are the maximum numbers of objects in the manifest.
+	limits Limits
+	// globalServiceNames records the first use of each service name, when
+	// service names must be unique across environments, it is nil otherwise.
+	globalServiceNames map[string]serviceEntry
+	// workers is the number of environments that are validated concurrently.
+	workers int
+	// requireHTTPS reports Git URLs that are not HTTPS URLs.
+	requireHTTPS bool
+	// names derives the names of the generated resources that are validated.
+	names Names
+	// cache has the forked visitors of the environments validated by a
+	// Validator, it is nil for the other validation.
+	cache *environmentCache
+	// forked is true for the visitors that validate a single environment
+	// concurrently with the others.
+	forked bool
+}
+
+// walkState is the state that the visitor builds up while visiting the
+// environments. A forked visitor starts with an empty walkState, and it is
+// merged into the visitor that forked it.
+//
+// Fields that are added must be handled by empty and merge in
+// validate_concurrent.go.
+type walkState struct {
+	errs     []error
+	warnings []string
+	// indexedPaths maps the name-based path of each object to the indexed path
+	// of the first object with that path, when errors are reported with
+	// indexed paths. Objects are compared by their name-based paths, so that
+	// duplicates are detected in the same way.
+	indexedPaths map[string]string
+	// envNames, appNames and serviceNames map the names that have been seen
+	// to the paths where they were first seen.
+	envNames     map[string]string
+	appNames     map[string]string
+	serviceNames map[string]string
+	serviceURLs  sourceRepositories
+	// foreignURLs are the service URLs that can have a different Git type to
+	// the GitOps repo.
+	foreignURLs map[string]bool
+	// configRepos maps the normalized URL of each application config_repo to
+	// the paths that reference it.
+	configRepos map[string][]string
+	// clusterNamespaces maps each cluster and namespace assigned to an
+	// environment to the path of the environment.
+	clusterNamespaces map[string]string
+	// usedBindings are the TriggerBindings referenced by the pipelines.
+	usedBindings map[string]bool
+	// environmentBindings maps the names of the environments to the
+	// TriggerBindings referenced by their pipelines, which the services in
+	// the environment can also reference.
+	environmentBindings map[string]map[string]bool
+	// serviceBindings are the TriggerBindings referenced by the pipelines of
+	// the services, they are resolved once the environments are visited.
+	serviceBindings []bindingRef
+	// environments and serviceEnvironments record where services are deployed
+	// for the ManifestIndex.
+	environments        []string
+	serviceEnvironments map[string][]string
+	// webhookPaths are the paths of the services with webhooks.
+	webhookPaths []string
+	// argoCDNames maps the names of the Argo CD applications generated for
+	// the environments and applications to their paths.
+	argoCDNames map[string][]string
+	// webhookSecrets maps the namespace and name of each webhook secret to
+	// the paths of the services that use it, unless they share it.
+	webhookSecrets map[string][]string
+	// applications is the number of applications in the manifest.
+	applications int
+	// namespaces maps the namespaces of the environments without a cluster
+	// to the paths of the environments, the environments with a cluster are
+	// checked by checkClusterNamespace.
+	namespaces map[string][]string
+	// promotions maps the names of the environments that promote to other
+	// environments to the promotion.
+	promotions map[string]promotion
+	// truncatedNames maps long service names to their truncated names, when
+	// long service names are truncated, it is nil otherwise.
+	truncatedNames map[string]string
+	// foldedNames maps each scope and name in lower case to the names that
+	// are the same without case, when names are compared without case, it is
+	// nil otherwise.
+	foldedNames map[string][]foldedName
+	// truncatedPaths records the first service truncated to each name.
+	truncatedPaths map[string]truncatedEntry
+	// deferred are the checks that depend on the state shared between
+	// environments, they are recorded when environments are validated
+	// concurrently, and made when the results are merged.
+	deferred []deferredCheck
+}
+
+// serviceEntry records where a service name was used.
+type serviceEntry struct {
+	env  string
+	path string
+}
+
+// truncatedEntry records the service name that was truncated.
+type truncatedEntry struct {
+	name string
+	path string
+}
+
+// ValidateOption configures the validation performed by Manifest.Validate.
+type ValidateOption func(*validateVisitor)
+
+// WithExtraValidators adds validators that are called with each element of the
+// manifest, the errors they return are merged with the errors from the core
+// validation.
+func WithExtraValidators(validators ...ManifestValidator) ValidateOption {
+	return func(vv *validateVisitor) {
+		vv.extraValidators = append(vv.extraValidators, validators...)
+	}
+}
+
+// WithGlobalServiceUniqueness requires each service name to be used in a single
+// environment, by default the same service can be deployed to several
+// environments.
+func WithGlobalServiceUniqueness() ValidateOption {
+	return func(vv *validateVisitor) {
+		vv.globalServiceNames = map[string]serviceEntry{}
+	}
+}
+
+// WithServiceNameTruncation truncates service names that exceed the limit with
+// TruncateServiceName, and reports a warning rather than an error.
+func WithServiceNameTruncation() ValidateOption {
+	return func(vv *validateVisitor) {
+		vv.truncatedNames = map[string]string{}
+		vv.truncatedPaths = map[string]truncatedEntry{}
+	}
+}
+
+// WithRequireHTTPS requires the gitops_url, and the URLs of the services and
+// config repositories to be HTTPS URLs, SSH and HTTP URLs are reported.
+func WithRequireHTTPS() ValidateOption {
+	return func(vv *validateVisitor) {
+		vv.requireHTTPS = true
+	}
+}
+
+// WithReservedNamespaces replaces the DefaultReservedNamespaces with the
+// patterns, in the path.Match syntax, for clusters with different conventions.
+func WithReservedNamespaces(patterns ...string) ValidateOption {
+	return func(vv *validateVisitor) {
+		vv.reservedNamespaces = patterns
+	}
+}
+
+// WithReservedPipelinesNames replaces the DefaultReservedPipelinesNames with
+// the patterns, in the path.Match syntax.
+func WithReservedPipelinesNames(patterns ...string) ValidateOption {
+	return func(vv *validateVisitor) {
+		vv.reservedPipelinesNames = patterns
+	}
+}
+
+// WithImageRegistries requires the image_repo of each service to be in one of
+// the registries e.g. quay.io, the InternalImageRegistry must be included for
+// image repositories without a registry.
+func WithImageRegistries(registries ...string) ValidateOption {
+	return func(vv *validateVisitor) {
+		vv.imageRegistries = map[string]bool{}
+		for _, r := range registries {
+			vv.imageRegistries[strings.ToLower(r)] = true
+		}
+	}
+}
+
+// TruncateServiceName returns the name if it is within the service name limit,
+// otherwise it returns a prefix of the name with a hash of the full name
+// appended, so that different names are unlikely to be truncated to the same
+// name.
+func TruncateServiceName(name string) string {
+	return Names{}.TruncateServiceName(name)
+}
+
+// TruncateServiceName returns the name if it is within the service name limit,
+// otherwise it returns the name shortened by the Sanitizer.
+func (n Names) TruncateServiceName(name string) string {
+	return n.sanitize(serviceNameLimit, name)
+}
+
+func newValidateVisitor() *validateVisitor {
+	return &validateVisitor{
+		walkState: walkState{
+			errs:         []error{},
+			warnings:     []string{},
+			envNames:     map[string]string{},
+			appNames:     map[string]string{},
+			serviceNames: map[string]string{},
+			serviceURLs:  sourceRepositories{},
+			foreignURLs:  map[string]bool{},
+			configRepos:  map[string][]string{},
+			usedBindings: map[string]bool{},
+
+			environmentBindings: map[string]map[string]bool{},
+
+			argoCDNames:    map[string][]string{},
+			webhookSecrets: map[string][]string{},
+			namespaces:     map[string][]string{},
+			promotions:     map[string]promotion{},
+
+			clusterNamespaces: map[string]string{},
+
+			environments:        []string{},
+			serviceEnvironments: map[string][]string{},
+		},
+		nameFunc:           validation.NameIsDNS1035Label,
+		configNames:        map[string]bool{},
+		reservedNamespaces: DefaultReservedNamespaces,
+		limits:             DefaultLimits,
+
+		reservedPipelinesNames: DefaultReservedPipelinesNames,
+	}
 }
 
 // Validate validates the Manifest, returning a multi-error representing all the
 // errors that were detected.
-func (m *Manifest) Validate() error {
-	vv := &validateVisitor{
-		errs:         []error{},
-		envNames:     map[string]bool{},
-		appNames:     map[string]bool{},
-		serviceNames: map[string]bool{},
-		serviceURLs:  map[string][]string{},
-		configNames:  map[string]bool{},
+func (m *Manifest) Validate(opts ...ValidateOption) error {
+	return m.validate(opts...).err()
+}
+
+// ValidateWithWarnings validates the Manifest in the same way as Validate, and
+// also returns advisories about parts of the manifest that are valid, but
+// likely to cause problems.
+func (m *Manifest) ValidateWithWarnings(opts ...ValidateOption) (errs error, warnings []string) {
+	vv := m.validate(opts...)
+	return vv.err(), vv.warnings
+}
+
+// ValidateAll validates each of the manifests, and also detects environment,
+// application and service names that are duplicated across manifests.
+//
+// The paths in the returned errors are prefixed with the index of the manifest
+// that they were found in e.g. manifests[1].environments.dev
+func ValidateAll(manifests []*Manifest) error {
+	errs := []error{}
+	names := &batchNames{seen: map[string]nameEntry{}}
+	for i, m := range manifests {
+		// Each manifest is validated on its own, and the names are compared
+		// with the other manifests separately.
+		vv := newValidateVisitor()
+		m.validateWith(vv)
+		for _, err := range vv.errs {
+			errs = append(errs, viaManifest(err, i))
+		}
+		names.source, names.errs = i, []error{}
+		// The manifest has been validated, and the visitor does not return
+		// errors.
+		_ = m.walk(context.Background(), names, Limits{}, neverStop)
+		errs = append(errs, names.errs...)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return multierror.Join(errs)
+}
+
+// ValidateWithIndexedPaths validates the Manifest in the same way as Validate,
+// but the paths in the errors identify objects by their position in the
+// manifest, rather than their name e.g. environments.0.apps.1.services.2.name
+func (m *Manifest) ValidateWithIndexedPaths() error {
+	// The defaults are applied first, so that Walk visits the indexed
+	// environments.
+	resolved := m.ApplyDefaults()
+	vv := newValidateVisitor()
+	vv.indices = indexManifest(resolved)
+	vv.indexedPaths = map[string]string{}
+	resolved.validateWith(vv)
+	return vv.err()
+}
+
+func (m *Manifest) validate(opts ...ValidateOption) *validateVisitor {
+	vv := newValidateVisitor()
+	for _, o := range opts {
+		o(vv)
 	}
+	m.validateWith(vv)
+	return vv
+}
 
+func (m *Manifest) validateWith(vv *validateVisitor) {
+	vv.errs = append(vv.errs, vv.validatePolicy()...)
 	vv.errs = append(vv.errs, vv.validateConfig(m)...)
-	err := m.Walk(vv)
-	if err != nil {
+	if vv.workers > 1 || vv.cache != nil {
+		vv.walkConcurrently(m)
+	} else if err := vv.walk(m, vv); err != nil {
 		vv.errs = append(vv.errs, err)
 	}
-	vv.errs = append(vv.errs, vv.validateServiceURLs(m.GitOpsURL)...)
+	vv.validateAcrossEnvironments(m)
+	for _, v := range vv.extraValidators {
+		if err := vv.walk(m, v); err != nil {
+			vv.errs = append(vv.errs, multierror.Split(err)...)
+		}
+	}
+}
+
+// validateAcrossEnvironments makes the checks that depend on the services and
+// applications of every environment, after they have been visited.
+func (vv *validateVisitor) validateAcrossEnvironments(m *Manifest) {
+	gitOpsURL := m.GitOpsURL
+	if gitOpsURL != "" {
+		if err := validateGitURL(gitOpsURL, "gitops_url"); err != nil {
+			vv.errs = append(vv.errs, err)
+			// The services can't be compared with an invalid URL.
+			gitOpsURL = ""
+		} else if err := vv.checkHTTPS(gitOpsURL, "gitops_url"); err != nil {
+			vv.errs = append(vv.errs, err)
+		}
+	}
+	vv.errs = append(vv.errs, vv.validateServiceURLs(gitOpsURL, !m.skipGitTypeValidation())...)
+	if m.GitOpsURL == "" && len(vv.webhookPaths) > 0 {
+		vv.errs = append(vv.errs, missingGitOpsURLError(vv.webhookPaths))
+	}
+	vv.errs = append(vv.errs, vv.validateWebhookSecrets()...)
+	vv.errs = append(vv.errs, vv.validateArgoCDNames(m)...)
+	vv.errs = append(vv.errs, vv.validateNamespaces()...)
+	vv.errs = append(vv.errs, vv.validateConfigRepoSelfReferences(m.GitOpsURL)...)
+	vv.errs = append(vv.errs, vv.validatePromotions()...)
+	vv.errs = append(vv.errs, vv.validateFoldedNames()...)
+	vv.errs = append(vv.errs, vv.validateServiceBindings()...)
+	if vv.applications > 0 && len(vv.serviceEnvironments) == 0 {
+		vv.warnRule(RuleNoServices, "environments", "the manifest has %d applications and no services, no pipelines are generated for the applications", vv.applications)
+	}
+}
 
+// manifestIndices records the position of each object in the manifest before
+// Walk reorders the environments.
+type manifestIndices struct {
+	envs     map[*Environment]int
+	apps     map[*Application]int
+	services map[*Service]int
+}
+
+func indexManifest(m *Manifest) *manifestIndices {
+	mi := &manifestIndices{
+		envs:     map[*Environment]int{},
+		apps:     map[*Application]int{},
+		services: map[*Service]int{},
+	}
+	for i, env := range m.Environments {
+		mi.envs[env] = i
+		for j, app := range env.Apps {
+			mi.apps[app] = j
+			for k, svc := range app.Services {
+				mi.services[svc] = k
+			}
+		}
+	}
+	return mi
+}
+
+func (vv *validateVisitor) pathForEnvironment(env *Environment) string {
+	if vv.indices == nil {
+		return yamlPath(PathForEnvironment(env))
+	}
+	return yamlJoin("environments", fmt.Sprint(vv.indices.envs[env]))
+}
+
+func (vv *validateVisitor) pathForApplication(env *Environment, app *Application) string {
+	if vv.indices == nil {
+		return yamlPath(PathForApplication(env, app))
+	}
+	return yamlJoin(vv.pathForEnvironment(env), "apps", fmt.Sprint(vv.indices.apps[app]))
+}
+
+func (vv *validateVisitor) pathForService(app *Application, env *Environment, svc *Service) string {
+	if vv.indices == nil {
+		return yamlPath(PathForService(app, env, svc.Name))
+	}
+	return yamlJoin(vv.pathForApplication(env, app), "services", fmt.Sprint(vv.indices.services[svc]))
+}
+
+// recordPath records the indexed path for the name-based path of an object,
+// and returns the name-based path, which identifies the object in the checks
+// that compare objects.
+func (vv *validateVisitor) recordPath(namePath, path string) string {
+	if vv.indexedPaths != nil {
+		if _, ok := vv.indexedPaths[namePath]; !ok {
+			vv.indexedPaths[namePath] = path
+		}
+	}
+	return namePath
+}
+
+// reportedPaths returns the paths that were recorded with recordPath, as they
+// are reported in errors.
+func (vv *validateVisitor) reportedPaths(paths []string) []string {
+	if vv.indexedPaths == nil {
+		return paths
+	}
+	reported := []string{}
+	for _, path := range paths {
+		if indexed, ok := vv.indexedPaths[path]; ok {
+			path = indexed
+		}
+		reported = append(reported, path)
+	}
+	return reported
+}
+
+// namePath returns the path to the name of the object at path, the name is
+// already part of the path when objects are not identified by index.
+func (vv *validateVisitor) namePath(path string) string {
+	if vv.indices == nil {
+		return path
+	}
+	return yamlJoin(path, "name")
+}
+
+func (vv *validateVisitor) err() error {
 	if len(vv.errs) == 0 {
 		return nil
 	}
-	return multierror.Join(vv.errs)
+	return multierror.Join(sortErrors(vv.errs))
 }
 
-func (vv *validateVisitor) validateServiceURLs(gitOpsURL string) []error {
+// sortErrors returns the errors sorted by their first path, and then by
+// message, errors without paths are sorted first.
+func sortErrors(errs []error) []error {
+	sorted := append([]error{}, errs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		pi, mi := errorSortKey(sorted[i])
+		pj, mj := errorSortKey(sorted[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return mi < mj
+	})
+	return sorted
+}
+
+func errorSortKey(err error) (string, string) {
+	var fe *apis.FieldError
+	if errors.As(err, &fe) && len(fe.Paths) > 0 {
+		return fe.Paths[0], fe.Message
+	}
+	return "", err.Error()
+}
+
+func (vv *validateVisitor) warn(path, format string, a ...interface{}) {
+	vv.warnings = append(vv.warnings, fmt.Sprintf("%s: %s", path, fmt.Sprintf(format, a...)))
+}
+
+// validateServiceURLs reports the source URLs that are used by several
+// services, and if checkGitType is true, the services that are not hosted by
+// the same Git type as the GitOps repository, and warns about the services
+// that can't have webhooks.
+//
+// If no driver is known for the GitOps repository host, the services on the
+// same host are assumed to have the same Git type, with a warning, and the
+// unknown driver is only reported if any of the services are on other hosts,
+// or there are no services on the same host.
+func (vv *validateVisitor) validateServiceURLs(gitOpsURL string, checkGitType bool) []error {
 	errs := []error{}
 
 	// all services must be the same git type as the gitops repo
 	var gitType string
+	// unknownHost is the GitOps repo host if no driver is known for it.
+	var unknownHost string
+	var unknownErr error
+	// sameHost and otherHosts count the services on the unknown host, and on
+	// other hosts.
+	var sameHost, otherHosts int
 
-	if gitOpsURL != "" {
+	if gitOpsURL != "" && checkGitType {
 		gitOpsDriver, err := scm.GetDriverName(gitOpsURL)
-		if err != nil {
+		var unknown *scm.UnknownDriverError
+		if errors.As(err, &unknown) {
+			unknownHost, unknownErr = unknown.Host, err
+		} else if err != nil {
 			errs = append(errs, err)
 		}
 		gitType = gitOpsDriver
 	}
 
-	for url, paths := range vv.serviceURLs {
-		if gitType != "" {
+	webhooks := map[string]bool{}
+	for _, path := range vv.webhookPaths {
+		webhooks[path] = true
+	}
+	urls := []string{}
+	for url := range vv.serviceURLs {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	for _, url := range urls {
+		paths := vv.serviceURLs[url]
+		foreign := vv.foreignURLs[url]
+		if unknownHost != "" && !foreign {
+			if host, err := scm.HostnameFromURL(url); err == nil && host == unknownHost {
+				sameHost++
+				for _, path := range paths {
+					vv.warnRule(RuleUncheckedGitType, path, "unable to check that %q is the same Git type as the GitOps repository", url)
+				}
+			} else {
+				otherHosts++
+			}
+		}
+		if checkGitType && (gitType != "" || foreign) {
 			serviceDriver, err := scm.GetDriverName(url)
 			if err != nil {
 				errs = append(errs, err)
-			} else if gitType != serviceDriver {
-				errs = append(errs, inconsistentGitTypeError(gitType, url, paths))
+			} else if gitType != "" && gitType != serviceDriver && !foreign {
+				if err := vv.ruleError(RuleGitType, inconsistentGitTypeError(gitType, url, paths)); err != nil {
+					errs = append(errs, err)
+				}
 			}
 		}
 		if len(paths) > 1 {
-			errs = append(errs, duplicateSourceError(url, paths))
+			if err := vv.ruleError(RuleDuplicateSource, duplicateSourceError(url, paths)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if driver, ok := webhooksNotSupported(url); ok {
+			for _, path := range paths {
+				if webhooks[yamlJoin(path, "webhook")] {
+					// Reported by RuleUnsupportedWebhook.
+					continue
+				}
+				vv.warnRule(RuleWebhooksNotSupported, path, "%s repositories don't support webhooks, the service will need to be triggered manually", driver)
+			}
 		}
 	}
+	if unknownErr != nil && (sameHost == 0 || otherHosts > 0) {
+		errs = append(errs, unknownErr)
+	}
 	return errs
 }
 
+// validateWebhookSecrets reports the webhook secrets that are used by several
+// services, as the webhooks would overwrite each other's configuration.
+func (vv *validateVisitor) validateWebhookSecrets() []error {
+	secrets := []string{}
+	for secret := range vv.webhookSecrets {
+		secrets = append(secrets, secret)
+	}
+	sort.Strings(secrets)
+	errs := []error{}
+	for _, secret := range secrets {
+		paths := vv.webhookSecrets[secret]
+		if len(paths) < 2 {
+			continue
+		}
+		if err := vv.ruleError(RuleDuplicateWebhookSecret, duplicateWebhookSecretError(secret, paths)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// validateArgoCDNames reports the Argo CD applications that would be generated
+// with the same name, as the later ones would overwrite the earlier ones in the
+// Argo CD namespace.
+func (vv *validateVisitor) validateArgoCDNames(m *Manifest) []error {
+	argoCD := m.GetArgoCDConfig()
+	if argoCD == nil {
+		return nil
+	}
+	names := map[string][]string{}
+	for name, paths := range vv.argoCDNames {
+		// Duplicate environments and applications have the same path, and
+		// are reported separately.
+		seen := map[string]bool{}
+		for _, path := range paths {
+			if !seen[path] {
+				seen[path] = true
+				names[name] = append(names[name], path)
+			}
+		}
+	}
+	if argoCD.Namespace != "" {
+		configPath := yamlPath(PathForArgoCD())
+		names[ArgoCDConfigAppName] = append([]string{configPath}, names[ArgoCDConfigAppName]...)
+		if m.GetPipelinesConfig() != nil {
+			names[ArgoCDCICDAppName] = append([]string{configPath}, names[ArgoCDCICDAppName]...)
+		}
+	}
+	sorted := []string{}
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	errs := []error{}
+	for _, name := range sorted {
+		if paths := names[name]; len(paths) > 1 {
+			errs = append(errs, argoCDNameCollisionError(name, vv.reportedPaths(paths)))
+		}
+	}
+	return errs
+}
+
+// validateNamespaces reports the environments that are deployed to the same
+// namespace, as their resources would overlap.
+func (vv *validateVisitor) validateNamespaces() []error {
+	sorted := []string{}
+	for namespace := range vv.namespaces {
+		sorted = append(sorted, namespace)
+	}
+	sort.Strings(sorted)
+	errs := []error{}
+	for _, namespace := range sorted {
+		// Duplicate environments have the same path, and are reported
+		// separately.
+		paths := []string{}
+		seen := map[string]bool{}
+		for _, path := range vv.namespaces[namespace] {
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+		if len(paths) > 1 {
+			errs = append(errs, sharedNamespaceError(namespace, vv.reportedPaths(paths)))
+		}
+	}
+	return errs
+}
+
+// validateConfigRepoSelfReferences reports the application config_repos that
+// are the GitOps repository, as Argo CD would never finish syncing.
+//
+// The manifest only records the references from the GitOps repository to the
+// config repositories, and not the references between config repositories, so
+// the only cycle that can be detected is a config_repo referring back to the
+// GitOps repository.
+func (vv *validateVisitor) validateConfigRepoSelfReferences(gitOpsURL string) []error {
+	if gitOpsURL == "" {
+		return nil
+	}
+	gitOps := normalizeGitURL(gitOpsURL)
+	paths, ok := vv.configRepos[gitOps]
+	if !ok {
+		return nil
+	}
+	return list(gitOpsConfigRepoError(gitOps, paths))
+}
+
 func (vv *validateVisitor) Environment(env *Environment) error {
-	envPath := yamlPath(PathForEnvironment(env))
+	envPath := vv.pathForEnvironment(env)
+	envKey := vv.recordPath(yamlPath(PathForEnvironment(env)), envPath)
+	vv.environments = append(vv.environments, env.Name)
+	vv.addArgoCDName(vv.names.ArgoCDEnvironmentName(env.Name), envKey, envPath, env.Name)
+	if env.PromotesTo != "" {
+		vv.promotions[env.Name] = promotion{to: env.PromotesTo, path: yamlJoin(envPath, "promotes_to")}
+	}
 	if _, ok := vv.configNames[env.Name]; ok {
 		vv.errs = append(vv.errs, invalidEnvironment(env.Name, "Environment name cannot be the same as a config name.", []string{envPath}))
 	}
-	if err := checkDuplicate(env.Name, envPath, vv.envNames); err != nil {
+	vv.shared(func(s *validateVisitor) error {
+		return s.checkDuplicate(env.Name, envKey, envPath, s.envNames)
+	})
+	vv.addFoldedName("environments", env.Name, envPath)
+	if err := vv.validateName(env.Name, vv.namePath(envPath)); err != nil {
 		vv.errs = append(vv.errs, err)
 	}
-	if err := validateName(env.Name, envPath); err != nil {
+	if err := validateNamePattern(env.Name, vv.namePath(envPath), vv.envNamePattern); err != nil {
 		vv.errs = append(vv.errs, err)
 	}
-	if err := validatePipelines(env.Pipelines, envPath); err != nil {
+	vv.errs = append(vv.errs, validateLabels(env.Labels, yamlJoin(envPath, "labels"))...)
+	if env.ArgoCDProject != "" {
+		if err := validateArgoCDProject(env.ArgoCDProject, yamlJoin(envPath, "argocd_project")); err != nil {
+			vv.errs = append(vv.errs, err)
+		}
+	}
+	namespace := EnvironmentNamespace(env)
+	if pattern, ok := vv.reservedNamespace(namespace); ok {
+		vv.errs = append(vv.errs, reservedNamespaceError(namespace, pattern, []string{envPath}))
+	}
+	// An environment with the same name as a config namespace is reported
+	// above, and environments in other clusters can use the same namespace.
+	if configPath, ok := vv.configNamespaces[namespace]; ok && !vv.configNames[env.Name] && env.Cluster == "" {
+		vv.errs = append(vv.errs, configNamespaceError(namespace, []string{envPath, configPath}))
+	}
+	if env.Cluster == "" {
+		vv.namespaces[namespace] = append(vv.namespaces[namespace], envKey)
+	} else {
+		if cluster, ok := normalizeClusterURL(env.Cluster); !ok {
+			vv.errs = append(vv.errs, invalidURLError(env.Cluster, clusterURLDetails, []string{yamlJoin(envPath, "cluster")}))
+		} else {
+			vv.shared(func(s *validateVisitor) error {
+				return s.checkClusterNamespace(cluster, namespace, envPath)
+			})
+		}
+	}
+	if err := vv.validatePipelines(env.Pipelines, envPath, true); err != nil {
 		vv.errs = append(vv.errs, err...)
 	}
+	if env.Pipelines != nil && env.Pipelines.Integration != nil {
+		bindings := map[string]bool{}
+		for _, name := range env.Pipelines.Integration.Bindings {
+			bindings[name] = true
+		}
+		vv.environmentBindings[env.Name] = bindings
+	}
+	if len(env.Apps) == 0 {
+		vv.warnRule(RuleEnvironmentWithoutApps, envPath, "environment %q has no applications", env.Name)
+	}
 	return nil
 }
 
+// validateLabels checks that the keys and values of the labels are valid
+// Kubernetes labels.
+func validateLabels(labels map[string]string, path string) []error {
+	keys := []string{}
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	errs := []error{}
+	for _, key := range keys {
+		labelPath := yamlJoin(path, key)
+		if reasons := k8svalidation.IsQualifiedName(key); len(reasons) > 0 {
+			errs = append(errs, invalidLabelKeyError(key, reasons[0], []string{labelPath}))
+		}
+		if reasons := k8svalidation.IsValidLabelValue(labels[key]); len(reasons) > 0 {
+			errs = append(errs, invalidLabelValueError(key, labels[key], reasons[0], []string{labelPath}))
+		}
+	}
+	return errs
+}
+
+// validateArgoCDProject checks that the name of the Argo CD project is a DNS
+// label, regardless of the name policy, as it is not a name from the manifest.
+func validateArgoCDProject(project, path string) error {
+	if reasons := k8svalidation.IsDNS1123Label(project); len(reasons) > 0 {
+		return invalidNameError(project, reasons[0], []string{path})
+	}
+	return nil
+}
+
+// checkClusterNamespace records the environment as using the namespace in the
+// cluster, and returns an error if another environment has already used it.
+func (vv *validateVisitor) checkClusterNamespace(cluster, namespace, path string) error {
+	key := cluster + " " + namespace
+	previous, ok := vv.clusterNamespaces[key]
+	if !ok {
+		vv.clusterNamespaces[key] = path
+		return nil
+	}
+	return clusterNamespaceError(cluster, namespace, []string{previous, path})
+}
+
+// reservedNamespace returns the first reserved namespace pattern that matches
+// the namespace.
+func (vv *validateVisitor) reservedNamespace(namespace string) (string, bool) {
+	return matchPattern(vv.reservedNamespaces, namespace)
+}
+
+// matchPattern returns the first of the path.Match patterns that matches the
+// name.
+func matchPattern(patterns []string, name string) (string, bool) {
+	for _, pattern := range patterns {
+		if ok, _ := gopath.Match(pattern, name); ok {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// addArgoCDName records the name of an Argo CD application that is generated
+// for the object with the key, and reports it at the path if it is not a valid
+// name.
+func (vv *validateVisitor) addArgoCDName(name, key, path string, parts ...string) {
+	vv.validateGeneratedName(name, "Argo CD application", path, k8svalidation.IsDNS1123Subdomain, parts...)
+	vv.argoCDNames[name] = append(vv.argoCDNames[name], key)
+}
+
+// validateGeneratedName reports a name generated from the parts that is not
+// valid, e.g. because the sanitizer did not shorten it enough.
+//
+// Names generated from invalid parts are not reported, as the parts are. It
+// returns true if the name was reported.
+func (vv *validateVisitor) validateGeneratedName(name, kind, path string, valid func(string) []string, parts ...string) bool {
+	for _, part := range parts {
+		if len(vv.nameFunc(part, false)) > 0 {
+			return false
+		}
+	}
+	if reasons := valid(name); len(reasons) > 0 {
+		vv.errs = append(vv.errs, invalidGeneratedNameError(name, kind, reasons[0], []string{path}))
+		return true
+	}
+	return false
+}
+
 func (vv *validateVisitor) Application(env *Environment, app *Application) error {
-	appPath := yamlPath(PathForApplication(env, app))
-	if err := checkDuplicate(app.Name, appPath, vv.appNames); err != nil {
+	appPath := vv.pathForApplication(env, app)
+	appKey := vv.recordPath(yamlPath(PathForApplication(env, app)), appPath)
+	vv.applications++
+	vv.addArgoCDName(vv.names.ArgoCDApplicationName(env.Name, app.Name), appKey, appPath, env.Name, app.Name)
+	vv.shared(func(s *validateVisitor) error {
+		return s.checkDuplicate(app.Name, appKey, appPath, s.appNames)
+	})
+	vv.addFoldedName(vv.pathForEnvironment(env), app.Name, appPath)
+	if err := vv.validateName(app.Name, vv.namePath(appPath)); err != nil {
 		vv.errs = append(vv.errs, err)
 	}
-	if err := validateName(app.Name, appPath); err != nil {
+	if err := validateNamePattern(app.Name, vv.namePath(appPath), vv.appNamePattern); err != nil {
 		vv.errs = append(vv.errs, err)
 	}
+	if vv.configNames[app.Name] {
+		vv.errs = append(vv.errs, invalidNameError(app.Name, configNameCollision, []string{appPath}))
+	}
 
 	if len(app.Services) == 0 && app.ConfigRepo == nil {
 		vv.errs = append(vv.errs, missingFieldsError([]string{"services", "config_repo"}, []string{appPath}))
@@ -112,9 +904,24 @@ func (vv *validateVisitor) Application(env *Environment, app *Application) error
 	if len(app.Services) > 0 && app.ConfigRepo != nil {
 		vv.errs = append(vv.errs, apis.ErrMultipleOneOf(yamlJoin(appPath, "services"), yamlJoin(appPath, "config_repo")))
 	}
+	if len(app.Services) > 0 && app.ConfigRepo == nil && !hasSourceURL(app.Services) {
+		if err := vv.ruleError(RuleApplicationWithoutSource, missingPipelineSourceError(app.Name, []string{appPath})); err != nil {
+			vv.errs = append(vv.errs, err)
+		}
+	}
 
 	if app.ConfigRepo != nil {
 		vv.errs = append(vv.errs, validateConfigRepo(app.ConfigRepo, yamlJoin(appPath, "config_repo"))...)
+		if err := vv.checkHTTPS(app.ConfigRepo.URL, yamlJoin(appPath, "config_repo", "url")); err != nil {
+			vv.errs = append(vv.errs, err)
+		}
+		if app.ConfigRepo.URL != "" {
+			repo := normalizeGitURL(app.ConfigRepo.URL)
+			vv.configRepos[repo] = append(vv.configRepos[repo], yamlJoin(appPath, "config_repo", "url"))
+		}
+		if env.Pipelines == nil {
+			vv.warnRule(RuleConfigRepoWithoutPipelines, appPath, "application %q has a config_repo but environment %q has no pipelines", app.Name, env.Name)
+		}
 	}
 	if len(app.Services) > 0 {
 		for _, r := range app.Services {
@@ -127,34 +934,178 @@ func (vv *validateVisitor) Application(env *Environment, app *Application) error
 	return nil
 }
 
+// hasSourceURL returns true if any of the services has a source_url.
+func hasSourceURL(services []*Service) bool {
+	for _, svc := range services {
+		if svc.SourceURL != "" {
+			return true
+		}
+	}
+	return false
+}
+
 func (vv *validateVisitor) Service(app *Application, env *Environment, svc *Service) error {
-	svcPath := yamlPath(PathForService(app, env, svc.Name))
+	svcPath := vv.pathForService(app, env, svc)
+	svcKey := vv.recordPath(yamlPath(PathForService(app, env, svc.Name)), svcPath)
 	svcRelativePath := yamlPath(filepath.Join(env.Name, svc.Name))
+	vv.serviceEnvironments[svc.Name] = append(vv.serviceEnvironments[svc.Name], env.Name)
 	if svc.SourceURL != "" {
-		previous, ok := vv.serviceURLs[svc.SourceURL]
-		if !ok {
-			previous = []string{}
+		sourceURL := vv.serviceURLs.add(svc.SourceURL, svcPath)
+		if svc.AllowForeignGitType {
+			vv.foreignURLs[sourceURL] = true
+		}
+		if err := vv.checkHTTPS(svc.SourceURL, yamlJoin(svcPath, "source_url")); err != nil {
+			vv.errs = append(vv.errs, err)
 		}
-		previous = append(previous, svcPath)
-		vv.serviceURLs[svc.SourceURL] = previous
 	}
-	if err := checkDuplicateService(svc.Name, svcPath, svcRelativePath, vv.serviceNames); err != nil {
+	vv.shared(func(s *validateVisitor) error {
+		return s.checkDuplicateService(svc.Name, svcRelativePath, svcKey, svcPath)
+	})
+	if vv.globalServiceNames != nil {
+		vv.shared(func(s *validateVisitor) error {
+			if previous, ok := s.globalServiceNames[svc.Name]; !ok {
+				s.globalServiceNames[svc.Name] = serviceEntry{env: env.Name, path: svcPath}
+			} else if previous.env != env.Name {
+				return duplicateFieldsError([]string{svc.Name}, []string{previous.path, svcPath})
+			}
+			return nil
+		})
+	}
+	if err := vv.validateName(svc.Name, vv.namePath(svcPath)); err != nil {
 		vv.errs = append(vv.errs, err)
 	}
-	if err := validateName(svc.Name, svcPath); err != nil {
+	if err := validateNamePattern(svc.Name, vv.namePath(svcPath), vv.serviceNamePattern); err != nil {
 		vv.errs = append(vv.errs, err)
 	}
+	if vv.configNames[svc.Name] {
+		vv.errs = append(vv.errs, invalidNameError(svc.Name, configNameCollision, []string{svcPath}))
+	}
 
-	if len(svc.Name) > serviceNameLimit {
-		vv.errs = append(vv.errs, invalidNameError(svc.Name, longServiceName, []string{svcPath}))
+	if len(svc.Name) > serviceNameLimit && vv.truncatedNames != nil {
+		vv.truncateServiceName(svc.Name, svcPath)
+	} else if len(svc.Name) > serviceNameLimit {
+		if err := vv.ruleError(RuleNameTooLong, invalidNameError(svc.Name, longServiceName, []string{svcPath})); err != nil {
+			vv.errs = append(vv.errs, err)
+		}
+	} else if len(svc.Name) > serviceNameLimit-serviceNameWarningMargin {
+		vv.warnRule(RuleNameNearLimit, svcPath, "service name %q is %d characters long, the limit is %d", svc.Name, len(svc.Name), serviceNameLimit)
 	}
-	if err := validateWebhook(svc.Webhook, svcPath); err != nil {
+	if svc.SourceURL != "" {
+		trigger := vv.names.TriggerName(env.Name, app.Name, svc.Name)
+		if !vv.validateGeneratedName(trigger, "trigger", svcPath, k8svalidation.IsDNS1123Label, svc.Name) && triggerNameTruncated(svc.Name, trigger) {
+			if len(triggerNamePrefix)+len(svc.Name)+1 > triggerNameLimit {
+				vv.warnRule(RuleTriggerNameTruncated, svcPath, "trigger name for service %q exceeds %d characters, it is truncated to %q", svc.Name, triggerNameLimit, trigger)
+			} else {
+				vv.warnRule(RuleTriggerNameTruncated, svcPath, "trigger name for service %q is changed to %q by the name sanitizer", svc.Name, trigger)
+			}
+		}
+	}
+	if svc.SourceURL != "" && svc.Webhook == nil {
+		if err := vv.ruleError(RuleMissingWebhook, missingFieldsError([]string{"webhook"}, []string{svcPath})); err != nil {
+			vv.errs = append(vv.errs, err)
+		}
+	}
+	if svc.ImageRepo != "" {
+		if err := vv.validateImageRepo(svc.ImageRepo, yamlJoin(svcPath, "image_repo")); err != nil {
+			vv.errs = append(vv.errs, err)
+		}
+	}
+	if err := vv.validateWebhook(svc.Webhook, svcPath); err != nil {
 		vv.errs = append(vv.errs, err...)
 	}
-	if err := validatePipelines(svc.Pipelines, svcPath); err != nil {
+	if err := vv.validateEnvVars(svc.Env, svcPath); err != nil {
 		vv.errs = append(vv.errs, err...)
 	}
-	vv.serviceNames[svc.Name] = true
+	if svc.Webhook != nil && app.ConfigRepo != nil {
+		vv.errs = append(vv.errs, configRepoWebhookError(svc.Name, app.Name, []string{yamlJoin(svcPath, "webhook")}))
+	}
+	if svc.Webhook != nil && svc.SourceURL != "" {
+		if driver, ok := webhooksNotSupported(svc.SourceURL); ok {
+			if err := vv.ruleError(RuleUnsupportedWebhook, unsupportedWebhookError(driver, []string{yamlJoin(svcPath, "webhook")})); err != nil {
+				vv.errs = append(vv.errs, err)
+			}
+		}
+		if provider := svc.Webhook.Provider; provider != "" && isSupportedDriver(provider) {
+			if driver, err := scm.GetDriverName(svc.SourceURL); err == nil && driver != provider {
+				vv.warnRule(RuleWebhookProvider, yamlJoin(svcPath, "webhook", "provider"), "the webhook is for %s, but %q is hosted by %s, the webhook won't trigger the pipelines", provider, svc.SourceURL, driver)
+			}
+		}
+	}
+	if svc.Webhook != nil {
+		vv.webhookPaths = append(vv.webhookPaths, yamlJoin(svcPath, "webhook"))
+		if svc.Webhook.Secret != nil && !svc.SharedWebhookSecret {
+			key := svc.Webhook.Secret.Namespace + "/" + svc.Webhook.Secret.Name
+			vv.webhookSecrets[key] = append(vv.webhookSecrets[key], svcPath)
+		}
+		if err := vv.validateSecretNamespace(env, svc, svcPath); err != nil {
+			if err := vv.ruleError(RuleSecretNamespace, err); err != nil {
+				vv.errs = append(vv.errs, err)
+			}
+		}
+	}
+	if err := vv.validatePipelines(svc.Pipelines, svcPath, false); err != nil {
+		vv.errs = append(vv.errs, err...)
+	}
+	if svc.Pipelines != nil && svc.Pipelines.Integration != nil {
+		imageBinding := ServiceImageBindingName(env.Name, app.Name, svc.Name)
+		for _, name := range svc.Pipelines.Integration.Bindings {
+			if name != imageBinding {
+				vv.serviceBindings = append(vv.serviceBindings, bindingRef{
+					env: env.Name, name: name, path: yamlJoin(svcPath, "pipelines", "integration", "bindings")})
+			}
+		}
+	}
+	if err := vv.validateBindingOverrides(env, svc, svcPath); err != nil {
+		vv.errs = append(vv.errs, err...)
+	}
+	vv.addFoldedName(yamlJoin(vv.pathForEnvironment(env), "services"), svc.Name, svcPath)
+	vv.serviceNames[svc.Name] = svcPath
+	return nil
+}
+
+// truncateServiceName records the truncated name for a long service name, two
+// different names that truncate to the same name are reported as duplicates.
+func (vv *validateVisitor) truncateServiceName(name, path string) {
+	truncated := vv.names.TruncateServiceName(name)
+	vv.warn(path, "service name %q exceeds %d characters, it is truncated to %q", name, serviceNameLimit, truncated)
+	vv.truncatedNames[name] = truncated
+	vv.shared(func(s *validateVisitor) error {
+		previous, ok := s.truncatedPaths[truncated]
+		if !ok {
+			s.truncatedPaths[truncated] = truncatedEntry{name: name, path: path}
+			return nil
+		}
+		if previous.name != name {
+			return duplicateFieldsError([]string{truncated}, []string{previous.path, path})
+		}
+		return nil
+	})
+}
+
+// validateImageRepo checks that the image repository is a registry, namespace
+// and name, or a project and application in the internal registry, and that
+// the registry is allowed.
+func (vv *validateVisitor) validateImageRepo(repo, path string) *apis.FieldError {
+	components := strings.Split(repo, "/")
+	registry := InternalImageRegistry
+	switch {
+	case len(components) == 3:
+		registry = components[0]
+		components = components[1:]
+	case len(components) != 2, strings.ContainsAny(components[0], ".:"):
+		return invalidImageRepoError(repo, imageRepoFormatDetails, []string{path})
+	}
+	if !imageRegistryRE.MatchString(registry) {
+		return invalidImageRepoError(repo, fmt.Sprintf("%q is not a valid registry host", registry), []string{path})
+	}
+	for _, c := range components {
+		if !imagePathRE.MatchString(c) {
+			return invalidImageRepoError(repo, fmt.Sprintf("%q must consist of lower case alphanumeric characters separated by '.', '_' or '-'", c), []string{path})
+		}
+	}
+	if vv.imageRegistries != nil && !vv.imageRegistries[strings.ToLower(registry)] {
+		return invalidImageRepoError(repo, fmt.Sprintf("the registry %q is not one of the allowed registries", registry), []string{path})
+	}
 	return nil
 }
 
@@ -170,62 +1121,348 @@ func validateConfigRepo(repo *Repository, path string) []error {
 	if len(missingFields) > 0 {
 		errs = append(errs, missingFieldsError(missingFields, []string{path}))
 	}
+	if repo.URL != "" {
+		if err := validateGitURL(repo.URL, yamlJoin(path, "url")); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if isGlob(repo.Path) {
+		if _, err := gopath.Match(repo.Path, ""); err != nil {
+			errs = append(errs, invalidGlobPatternError(repo.Path, err.Error(), []string{yamlJoin(path, "path")}))
+		}
+	} else if repo.Path != "" && !isKustomizationPath(repo.Path) {
+		errs = append(errs, invalidConfigRepoPathError(repo.Path, []string{yamlJoin(path, "path")}))
+	}
+	if repo.TargetRevision != "" {
+		if reason := checkGitRef(repo.TargetRevision); reason != "" {
+			errs = append(errs, invalidGitRefError(repo.TargetRevision, reason, []string{yamlJoin(path, "target_revision")}))
+		}
+	}
+	return errs
+}
+
+// checkGitRef returns the reason that the ref is not a valid Git branch, tag
+// or commit, following the rules of git check-ref-format, or an empty string
+// if it is valid.
+func checkGitRef(ref string) string {
+	switch {
+	case ref == "@":
+		return `a ref cannot be "@"`
+	case strings.HasPrefix(ref, "/") || strings.HasSuffix(ref, "/"):
+		return `a ref cannot begin or end with "/"`
+	case strings.HasSuffix(ref, "."):
+		return `a ref cannot end with "."`
+	case strings.Contains(ref, ".."), strings.Contains(ref, "@{"), strings.Contains(ref, "//"):
+		return `a ref cannot contain "..", "@{" or "//"`
+	}
+	for _, r := range ref {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(" ~^:?*[\\", r) {
+			return fmt.Sprintf("a ref cannot contain %q", r)
+		}
+	}
+	for _, component := range strings.Split(ref, "/") {
+		if strings.HasPrefix(component, ".") || strings.HasSuffix(component, ".lock") {
+			return `a ref component cannot begin with "." or end with ".lock"`
+		}
+	}
+	return ""
+}
+
+// kustomizationFiles are the names of the files that Kustomize reads from a
+// directory.
+var kustomizationFiles = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// isKustomizationPath returns true if the path is a directory, or a
+// kustomization file, rather than another YAML file.
+func isKustomizationPath(path string) bool {
+	base := gopath.Base(strings.TrimSuffix(path, "/"))
+	for _, name := range kustomizationFiles {
+		if base == name {
+			return true
+		}
+	}
+	ext := strings.ToLower(gopath.Ext(base))
+	return ext != ".yaml" && ext != ".yml"
+}
+
+// isGlob returns true if the path contains any of the path.Match
+// metacharacters.
+func isGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[\\")
+}
+
+func (vv *validateVisitor) validateWebhook(hook *Webhook, path string) []error {
+	errs := []error{}
+	if hook == nil {
+		return nil
+	}
+	if hook.Provider != "" && !isSupportedDriver(hook.Provider) {
+		errs = append(errs, unknownWebhookProviderError(hook.Provider, []string{yamlJoin(path, "webhook", "provider")}))
+	}
+	if hook.Secret == nil {
+		return append(errs, emptyBlockError("webhook", []string{"secret"}, []string{yamlJoin(path, "webhook")}))
+	}
+	if err := vv.validateName(hook.Secret.Name, yamlJoin(path, "webhook", "secret", "name")); err != nil {
+		errs = append(errs, err)
+	}
+	if err := vv.validateName(hook.Secret.Namespace, yamlJoin(path, "webhook", "secret", "namespace")); err != nil {
+		errs = append(errs, err)
+	}
+	if hook.Secret.Key != "" {
+		if err := k8svalidation.IsConfigMapKey(hook.Secret.Key); len(err) > 0 {
+			errs = append(errs, invalidSecretKeyError(hook.Secret.Key, err[0], []string{yamlJoin(path, "webhook", "secret", "key")}))
+		}
+	}
+	return errs
+}
+
+// validateEnvVars checks the names of the environment variables of a service,
+// and the secrets that their values are read from, in the same way as the
+// webhook secrets.
+func (vv *validateVisitor) validateEnvVars(vars []EnvVar, path string) []error {
+	errs := []error{}
+	seen := map[string]bool{}
+	for _, v := range vars {
+		if v.Name == "" {
+			errs = append(errs, missingFieldsError([]string{"name"}, []string{yamlJoin(path, "env")}))
+			continue
+		}
+		varPath := yamlJoin(path, "env", v.Name)
+		if seen[v.Name] {
+			errs = append(errs, duplicateFieldsError([]string{v.Name}, []string{varPath}))
+			continue
+		}
+		seen[v.Name] = true
+		if !envVarNameRE.MatchString(v.Name) {
+			errs = append(errs, invalidEnvVarNameError(v.Name, []string{varPath}))
+		}
+		if v.ValueFrom == nil {
+			continue
+		}
+		if v.Value != "" {
+			errs = append(errs, apis.ErrMultipleOneOf(yamlJoin(varPath, "value"), yamlJoin(varPath, "value_from")))
+		}
+		secretPath := yamlJoin(varPath, "value_from")
+		if err := vv.validateName(v.ValueFrom.Name, yamlJoin(secretPath, "name")); err != nil {
+			errs = append(errs, err)
+		}
+		if v.ValueFrom.Namespace != "" {
+			if err := vv.validateName(v.ValueFrom.Namespace, yamlJoin(secretPath, "namespace")); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if v.ValueFrom.Key == "" {
+			errs = append(errs, missingFieldsError([]string{"key"}, []string{secretPath}))
+		} else if err := k8svalidation.IsConfigMapKey(v.ValueFrom.Key); len(err) > 0 {
+			errs = append(errs, invalidSecretKeyError(v.ValueFrom.Key, err[0], []string{yamlJoin(secretPath, "key")}))
+		}
+	}
+	return errs
+}
+
+// validateSecretNamespace checks that the webhook secret is in the namespace
+// of the environment or the pipelines, unless the service allows it to be in
+// another namespace.
+func (vv *validateVisitor) validateSecretNamespace(env *Environment, svc *Service, path string) *apis.FieldError {
+	if svc.AllowCrossNamespaceSecret || svc.Webhook.Secret == nil || svc.Webhook.Secret.Namespace == "" {
+		return nil
+	}
+	expected := []string{EnvironmentNamespace(env)}
+	if vv.pipelinesNamespace != "" && vv.pipelinesNamespace != expected[0] {
+		expected = append(expected, vv.pipelinesNamespace)
+	}
+	for _, ns := range expected {
+		if svc.Webhook.Secret.Namespace == ns {
+			return nil
+		}
+	}
+	return secretNamespaceError(svc.Webhook.Secret.Namespace, expected, []string{yamlJoin(path, "webhook", "secret", "namespace")})
+}
+
+// validatePipelines checks the pipelines of an environment or service, the
+// bindings are required for environments, services without bindings use the
+// bindings of their environment.
+func (vv *validateVisitor) validatePipelines(pipelines *Pipelines, path string, requireBindings bool) []error {
+	errs := []error{}
+	if pipelines == nil {
+		return nil
+	}
+	if pipelines.Integration == nil {
+		return list(emptyBlockError("pipelines", []string{"integration"}, []string{yamlJoin(path, "pipelines")}))
+	}
+	if requireBindings && len(pipelines.Integration.Bindings) == 0 && !pipelines.Integration.AllowEmptyBindings {
+		errs = append(errs, emptyBindingsError([]string{yamlJoin(path, "pipelines", "integration", "bindings")}))
+	}
+	if len(pipelines.Integration.Bindings) > 0 {
+		integrationPath := yamlJoin(path, "pipelines", "integration")
+		if pipelines.Integration.Template == "" {
+			errs = append(errs, missingFieldsError([]string{"template"}, []string{integrationPath}))
+		} else if err := vv.validateName(pipelines.Integration.Template, yamlJoin(integrationPath, "template")); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	seen := map[string]int{}
+	for _, name := range pipelines.Integration.Bindings {
+		bindingPath := yamlJoin(path, "pipelines", "integration", "bindings")
+		seen[name]++
+		if seen[name] > 1 {
+			if seen[name] == 2 {
+				errs = append(errs, duplicateFieldsError([]string{name}, []string{bindingPath}))
+			}
+			continue
+		}
+		vv.usedBindings[name] = true
+		if err := vv.validateName(name, bindingPath); err != nil {
+			errs = append(errs, err)
+		}
+		if vv.declaredBindings != nil && !vv.declaredBindings[name] {
+			errs = append(errs, missingBindingError(name, []string{bindingPath}))
+		}
+	}
 	return errs
 }
 
-func validateWebhook(hook *Webhook, path string) []error {
-	errs := []error{}
-	if hook == nil {
+// bindingRef is a reference to a TriggerBinding from the pipelines of a
+// service in the environment.
+type bindingRef struct {
+	env, name, path string
+}
+
+// validateServiceBindings reports the TriggerBindings referenced by services
+// that are neither referenced by the pipelines of their environment, nor
+// generated for the service, as the binding is unlikely to exist.
+//
+// If the pipelines config declares the bindings, the references are checked
+// against the declarations instead.
+func (vv *validateVisitor) validateServiceBindings() []error {
+	if vv.declaredBindings != nil {
 		return nil
 	}
-	if hook.Secret == nil {
-		return list(missingFieldsError([]string{"secret"}, []string{yamlJoin(path, "webhook")}))
-	}
-	if err := validateName(hook.Secret.Name, yamlJoin(path, "webhook", "secret", "name")); err != nil {
-		errs = append(errs, err)
-	}
-	if err := validateName(hook.Secret.Namespace, yamlJoin(path, "webhook", "secret", "namespace")); err != nil {
-		errs = append(errs, err)
+	errs := []error{}
+	reported := map[string]bool{}
+	for _, ref := range vv.serviceBindings {
+		key := ref.path + " " + ref.name
+		if vv.environmentBindings[ref.env][ref.name] || reported[key] {
+			continue
+		}
+		reported[key] = true
+		if err := vv.ruleError(RuleUndeclaredBinding, unresolvedBindingError(ref.name, ref.env, []string{ref.path})); err != nil {
+			errs = append(errs, err)
+		}
 	}
 	return errs
 }
 
-func validatePipelines(pipelines *Pipelines, path string) []error {
-	errs := []error{}
-	if pipelines == nil {
+// validateBindingOverrides checks the integration bindings of the service
+// against the bindings of its environment, a binding that is used by both with
+// different templates is ambiguous, and the same template and bindings are a
+// redundant override.
+func (vv *validateVisitor) validateBindingOverrides(env *Environment, svc *Service, path string) []error {
+	if env.Pipelines == nil || env.Pipelines.Integration == nil || svc.Pipelines == nil || svc.Pipelines.Integration == nil {
 		return nil
 	}
-	if pipelines.Integration == nil {
-		return list(missingFieldsError([]string{"integration"}, []string{yamlJoin(path, "pipelines")}))
+	envIntegration, svcIntegration := env.Pipelines.Integration, svc.Pipelines.Integration
+	template := svcIntegration.Template
+	if template == "" {
+		template = envIntegration.Template
 	}
-	for _, name := range pipelines.Integration.Bindings {
-		if err := validateName(name, yamlJoin(path, "pipelines", "integration", "binding")); err != nil {
+	if template == envIntegration.Template {
+		if equalBindings(svcIntegration.Bindings, envIntegration.Bindings) {
+			vv.warnRule(RuleRedundantPipelines, yamlJoin(path, "pipelines"), "service %q has the same integration template and bindings as environment %q", svc.Name, env.Name)
+		}
+		return nil
+	}
+	envBindings := map[string]bool{}
+	for _, name := range envIntegration.Bindings {
+		envBindings[name] = true
+	}
+	errs := []error{}
+	envBindingPath := yamlJoin(vv.pathForEnvironment(env), "pipelines", "integration", "bindings")
+	svcBindingPath := yamlJoin(path, "pipelines", "integration", "bindings")
+	for _, name := range svcIntegration.Bindings {
+		if !envBindings[name] {
+			continue
+		}
+		// Each binding is only reported once.
+		delete(envBindings, name)
+		if err := vv.ruleError(RuleBindingCollision, bindingCollisionError(name, []string{envBindingPath, svcBindingPath})); err != nil {
 			errs = append(errs, err)
 		}
 	}
 	return errs
 }
+
+func equalBindings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func (vv *validateVisitor) validateConfig(manifest *Manifest) []error {
 	errs := []error{}
+	vv.configNamespaces = configNamespaces(manifest.Config)
+	for namespace, path := range vv.configNamespaces {
+		// The environment names are also their namespaces.
+		vv.addFoldedName("environments", namespace, path)
+	}
 	if manifest.Config != nil {
+		switch manifest.Config.NamePolicy {
+		case "", NamePolicyDNS1035:
+			vv.nameFunc = validation.NameIsDNS1035Label
+		case NamePolicyDNS1123:
+			vv.nameFunc = validation.NameIsDNSLabel
+		default:
+			errs = append(errs, invalidNamePolicyError(manifest.Config.NamePolicy, []string{"config.name_policy"}))
+		}
 		if manifest.Config.ArgoCD != nil {
-			if err := validateName(manifest.Config.ArgoCD.Namespace, yamlPath(PathForArgoCD())); err != nil {
+			if err := vv.validateName(manifest.Config.ArgoCD.Namespace, yamlPath(PathForArgoCD())); err != nil {
 				errs = append(errs, err)
 			}
 			vv.configNames[manifest.Config.ArgoCD.Namespace] = true
+			if manifest.Config.ArgoCD.Project != "" {
+				if err := validateArgoCDProject(manifest.Config.ArgoCD.Project, yamlJoin(yamlPath(PathForArgoCD()), "project")); err != nil {
+					errs = append(errs, err)
+				}
+			}
 		}
 		if manifest.Config.Pipelines != nil {
-			if err := validateName(manifest.Config.Pipelines.Name, yamlPath(PathForPipelines(manifest.Config.Pipelines))); err != nil {
+			pipelinesPath := yamlPath(PathForPipelines(manifest.Config.Pipelines))
+			if err := vv.validateName(manifest.Config.Pipelines.Name, pipelinesPath); err != nil {
 				errs = append(errs, err)
+			} else if pattern, ok := matchPattern(vv.reservedPipelinesNames, manifest.Config.Pipelines.Name); ok {
+				errs = append(errs, reservedPipelinesNameError(manifest.Config.Pipelines.Name, pattern, []string{pipelinesPath}))
 			}
 			vv.configNames[manifest.Config.Pipelines.Name] = true
+			vv.pipelinesNamespace = manifest.Config.Pipelines.Name
+			if manifest.Config.ArgoCD != nil && manifest.Config.ArgoCD.Namespace == manifest.Config.Pipelines.Name {
+				errs = append(errs, sameConfigNamespaceError(manifest.Config.Pipelines.Name,
+					[]string{yamlPath(PathForArgoCD()), yamlPath(PathForPipelines(manifest.Config.Pipelines))}))
+			}
+			if len(manifest.Config.Pipelines.Bindings) > 0 {
+				vv.declaredBindings = map[string]bool{}
+				for _, name := range manifest.Config.Pipelines.Bindings {
+					vv.declaredBindings[name] = true
+				}
+			}
 		}
 	}
 	return errs
 }
 
-func validateName(name, path string) *apis.FieldError {
-	err := validation.NameIsDNS1035Label(name, true)
+// validateName checks that the name is valid for the name policy, names with
+// leading or trailing whitespace are reported separately, as the whitespace
+// is easy to miss in the DNS label error.
+func (vv *validateVisitor) validateName(name, path string) error {
+	if hasWhitespace(name) {
+		return whitespaceNameError(name, []string{path})
+	}
+	err := vv.nameFunc(name, true)
 	if len(err) > 0 {
 		return invalidNameError(name, err[0], []string{path})
 	}
@@ -236,6 +1473,93 @@ func yamlPath(path string) string {
 	return strings.ReplaceAll(path, "/", ".")
 }
 
+// normalizeGitURL reduces a Git repository URL to host and path, so that the
+// HTTPS and SSH forms of the same repository compare equal.
+//
+// e.g. both https://github.com/org/repo and git@github.com:org/repo.git become
+// github.com/org/repo.
+func normalizeGitURL(rawURL string) string {
+	canonical, err := scm.CanonicalURL(rawURL)
+	if err != nil {
+		return strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(rawURL), "/"), ".git")
+	}
+	return strings.TrimPrefix(canonical, "https://")
+}
+
+// canonicalGitURL returns the scm.CanonicalURL for the Git repository URL, or
+// the URL unchanged if it is not a Git repository URL.
+func canonicalGitURL(rawURL string) string {
+	canonical, err := scm.CanonicalURL(rawURL)
+	if err != nil {
+		return strings.TrimSpace(rawURL)
+	}
+	return canonical
+}
+
+// webhooksNotSupported returns the driver of the source URL, and true if the
+// driver can't deliver webhooks, URLs that can't be identified are assumed to
+// support webhooks.
+func webhooksNotSupported(sourceURL string) (string, bool) {
+	driver, err := scm.GetDriverName(sourceURL)
+	if err != nil {
+		return "", false
+	}
+	return driver, !scm.SupportsWebhooks(driver)
+}
+
+// isSupportedDriver returns true if the driver is one of the Git drivers that
+// source URLs can be identified as.
+func isSupportedDriver(driver string) bool {
+	for _, d := range scm.SupportedDrivers() {
+		if d == driver {
+			return true
+		}
+	}
+	return false
+}
+
+// validateGitURL returns an error if the URL is not an HTTPS or SSH Git
+// repository URL with a host.
+func validateGitURL(rawURL, path string) *apis.FieldError {
+	if _, err := scm.CanonicalURL(rawURL); err != nil {
+		return invalidURLError(rawURL, gitURLDetails, []string{path})
+	}
+	return nil
+}
+
+// checkHTTPS returns an error if HTTPS URLs are required, and the Git URL is
+// not an HTTPS URL, the error suggests the HTTPS form of the URL.
+//
+// URLs that are not valid Git URLs are reported by validateGitURL.
+func (vv *validateVisitor) checkHTTPS(rawURL, path string) *apis.FieldError {
+	if !vv.requireHTTPS || rawURL == "" {
+		return nil
+	}
+	canonical, err := scm.CanonicalURL(rawURL)
+	if err != nil {
+		return nil
+	}
+	trimmed := strings.ToLower(strings.TrimSpace(rawURL))
+	switch {
+	case strings.HasPrefix(trimmed, "https://"):
+		return nil
+	case strings.Contains(trimmed, "://") && !strings.HasPrefix(trimmed, "ssh://"):
+		return insecureURLError(rawURL, fmt.Sprintf("only HTTPS URLs are allowed, use %q", canonical), []string{path})
+	}
+	return insecureURLError(rawURL, fmt.Sprintf("SSH URLs are not allowed, use %q", canonical), []string{path})
+}
+
+// normalizeClusterURL returns the cluster API server URL with the host
+// lowercased and without a trailing slash, or false if it is not an HTTP(S)
+// URL with a host.
+func normalizeClusterURL(rawURL string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return "", false
+	}
+	return u.Scheme + "://" + strings.ToLower(u.Host) + strings.TrimSuffix(u.Path, "/"), true
+}
+
 func yamlJoin(a string, b ...string) string {
 	for _, s := range b {
 		a = a + "." + s
@@ -247,36 +1571,246 @@ func list(errs ...error) []error {
 	return errs
 }
 
-func invalidEnvironment(name, details string, paths []string) *apis.FieldError {
-	return &apis.FieldError{
+func invalidEnvironment(name, details string, paths []string) *categorizedError {
+	return categorized(CategoryName, &apis.FieldError{
 		Message: fmt.Sprintf("invalid environment %q", name),
 		Details: details,
 		Paths:   paths,
+	})
+}
+
+func invalidNameError(name, details string, paths []string) *categorizedError {
+	return categorized(CategoryName, &apis.FieldError{
+		Message: fmt.Sprintf("invalid name %q", name),
+		Details: details,
+		Paths:   paths,
+	})
+}
+
+func whitespaceNameError(name string, paths []string) *categorizedError {
+	return categorized(CategoryName, &apis.FieldError{
+		Message: fmt.Sprintf("name %q has leading or trailing whitespace", name),
+		Paths:   paths,
+	})
+}
+
+func invalidGeneratedNameError(name, kind, details string, paths []string) *categorizedError {
+	return categorized(CategoryName, &apis.FieldError{
+		Message: fmt.Sprintf("invalid %s name %q generated", kind, name),
+		Details: details,
+		Paths:   paths,
+	})
+}
+
+func invalidSecretKeyError(key, details string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid secret key %q", key),
+		Details: details,
+		Paths:   paths,
 	}
 }
 
-func invalidNameError(name, details string, paths []string) *apis.FieldError {
+func insecureURLError(url, details string, paths []string) *apis.FieldError {
 	return &apis.FieldError{
-		Message: fmt.Sprintf("invalid name %q", name),
+		Message: fmt.Sprintf("URL %q is not an HTTPS URL", url),
 		Details: details,
 		Paths:   paths,
 	}
 }
 
-func missingFieldsError(fields, paths []string) *apis.FieldError {
+func invalidURLError(url, details string, paths []string) *apis.FieldError {
 	return &apis.FieldError{
-		Message: fmt.Sprintf("missing field(s) %v", strings.Join(addQuotes(fields...), ",")),
+		Message: fmt.Sprintf("invalid URL %q", url),
+		Details: details,
 		Paths:   paths,
 	}
 }
 
-func duplicateFieldsError(fields, paths []string) *apis.FieldError {
+func clusterNamespaceError(cluster, namespace string, paths []string) *apis.FieldError {
 	return &apis.FieldError{
-		Message: fmt.Sprintf("duplicate field(s) %v", strings.Join(addQuotes(fields...), ",")),
+		Message: fmt.Sprintf("environments cannot share the namespace %q in cluster %q", namespace, cluster),
+		Details: "the resources of the environments would collide",
+		Paths:   paths,
+	}
+}
+
+func configRepoWebhookError(service, app string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("service %q has a webhook, but application %q is deployed from a config_repo", service, app),
+		Details: "webhooks build services from their source, the services of applications with a config_repo are not built",
+		Paths:   paths,
+	}
+}
+
+func invalidEnvVarNameError(name string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid environment variable name %q", name),
+		Details: fmt.Sprintf("names must match %q", envVarNameRE.String()),
+		Paths:   paths,
+	}
+}
+
+func invalidLabelKeyError(key, details string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid label key %q", key),
+		Details: details,
+		Paths:   paths,
+	}
+}
+
+func invalidLabelValueError(key, value, details string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid value %q for label %q", value, key),
+		Details: details,
+		Paths:   paths,
+	}
+}
+
+func sharedNamespaceError(namespace string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("environments cannot share the namespace %q", namespace),
+		Details: "the resources of the environments would overlap",
+		Paths:   paths,
+	}
+}
+
+func configNamespaceError(namespace string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("the environment namespace %q is also a config namespace", namespace),
+		Details: "the resources of the environment would be deployed to the same namespace as the resources for the config",
+		Paths:   paths,
+	}
+}
+
+func reservedNamespaceError(namespace, pattern string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("the namespace %q is reserved", namespace),
+		Details: fmt.Sprintf("environments cannot use namespaces matching %q", pattern),
+		Paths:   paths,
+	}
+}
+
+func reservedPipelinesNameError(name, pattern string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("the pipelines name %q is reserved", name),
+		Details: fmt.Sprintf("the namespace and resources created for the pipelines would conflict with the system resources in namespaces matching %q", pattern),
+		Paths:   paths,
+	}
+}
+
+func invalidGitRefError(ref, details string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid Git ref %q", ref),
+		Details: details,
+		Paths:   paths,
+	}
+}
+
+func invalidImageRepoError(repo, details string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid image repository %q", repo),
+		Details: details,
+		Paths:   paths,
+	}
+}
+
+func invalidNamePolicyError(policy string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid name policy %q", policy),
+		Details: fmt.Sprintf("the name policy must be one of %q or %q", NamePolicyDNS1035, NamePolicyDNS1123),
+		Paths:   paths,
+	}
+}
+
+func invalidConfigRepoPathError(path string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid config_repo path %q", path),
+		Details: "the path must be a kustomization directory, or a kustomization.yaml file",
+		Paths:   paths,
+	}
+}
+
+func invalidGlobPatternError(pattern, details string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid glob pattern %q", pattern),
+		Details: details,
+		Paths:   paths,
+	}
+}
+
+func secretNamespaceError(namespace string, expected []string, paths []string) *apis.FieldError {
+	quoted := []string{}
+	for _, ns := range expected {
+		quoted = append(quoted, strconv.Quote(ns))
+	}
+	return &apis.FieldError{
+		Message: fmt.Sprintf("the webhook secret is in namespace %q, which is not the namespace of the environment or the pipelines", namespace),
+		Details: fmt.Sprintf("the secret is expected to be in %s, set allow_cross_namespace_secret on the service to use another namespace", strings.Join(quoted, " or ")),
+		Paths:   paths,
+	}
+}
+
+func bindingCollisionError(binding string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("binding %q is used by the environment and the service with different templates", binding),
+		Details: "use a different binding for the service, or the same template as the environment",
+		Paths:   paths,
+	}
+}
+
+func missingBindingError(binding string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("binding %q is not declared in the pipelines config", binding),
+		Paths:   paths,
+	}
+}
+
+func unresolvedBindingError(binding, env string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("binding %q is not declared", binding),
+		Details: fmt.Sprintf("the binding must be referenced by the pipelines of the environment %q, or declared in the pipelines config", env),
+		Paths:   paths,
+	}
+}
+
+func missingFieldsError(fields, paths []string) *categorizedError {
+	return categorized(CategoryMissingField, &apis.FieldError{
+		Message: fmt.Sprintf("missing field(s) %v", strings.Join(addQuotes(fields...), ",")),
+		Paths:   paths,
+	})
+}
+
+// emptyBlockError is a missingFieldsError for a block that is in the manifest,
+// but has none of its fields e.g. "webhook:" followed by nothing.
+func emptyBlockError(block string, fields, paths []string) *categorizedError {
+	err := missingFieldsError(fields, paths)
+	err.Details = fmt.Sprintf("the %q block is present but empty", block)
+	return err
+}
+
+func emptyBindingsError(paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: "the integration has no bindings",
+		Details: "the triggers for the pipelines would have no bindings, set allow_empty_bindings if this is intended",
 		Paths:   paths,
 	}
 }
 
+func duplicateFieldsError(fields, paths []string) *categorizedError {
+	return categorized(CategoryDuplicate, &apis.FieldError{
+		Message: fmt.Sprintf("duplicate field(s) %v", strings.Join(addQuotes(fields...), ",")),
+		Paths:   paths,
+	})
+}
+
+func missingGitOpsURLError(paths []string) *categorizedError {
+	return categorized(CategoryMissingField, &apis.FieldError{
+		Message: `missing field(s) "gitops_url"`,
+		Details: "services with webhooks require a GitOps URL for the webhooks to be delivered to",
+		Paths:   paths,
+	})
+}
+
 func missingServiceError(app string, paths []string) *apis.FieldError {
 	return &apis.FieldError{
 		Message: fmt.Sprintf("missing service app %q", app),
@@ -284,17 +1818,72 @@ func missingServiceError(app string, paths []string) *apis.FieldError {
 	}
 }
 
-func duplicateSourceError(url string, paths []string) *apis.FieldError {
+func missingPipelineSourceError(app string, paths []string) *apis.FieldError {
 	return &apis.FieldError{
+		Message: fmt.Sprintf("application %q has no source to build", app),
+		Details: "at least one of the services must have a source_url, or the application must have a config_repo",
+		Paths:   paths,
+	}
+}
+
+func duplicateSourceError(url string, paths []string) *categorizedError {
+	return categorized(CategoryDuplicate, &apis.FieldError{
 		Message: fmt.Sprintf("duplicate source detected, multiple services cannot share the same source repository: %s", url),
 		Paths:   paths,
+	})
+}
+
+func unsupportedWebhookError(driver string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("%s repositories don't support webhooks", driver),
+		Details: "remove the webhook, the service will need to be triggered manually",
+		Paths:   paths,
 	}
 }
 
-func inconsistentGitTypeError(gitType, serviceURL string, paths []string) *apis.FieldError {
+func unknownWebhookProviderError(provider string, paths []string) *apis.FieldError {
 	return &apis.FieldError{
+		Message: fmt.Sprintf("unknown webhook provider %q", provider),
+		Details: fmt.Sprintf("the provider must be one of %s", strings.Join(scm.SupportedDrivers(), ", ")),
+		Paths:   paths,
+	}
+}
+
+func duplicateWebhookSecretError(secret string, paths []string) *categorizedError {
+	return categorized(CategoryDuplicate, &apis.FieldError{
+		Message: fmt.Sprintf("duplicate webhook secret detected, multiple services cannot share the same webhook secret: %s", secret),
+		Details: "set shared_webhook_secret on the services to share the secret",
+		Paths:   paths,
+	})
+}
+
+func argoCDNameCollisionError(name string, paths []string) *categorizedError {
+	return categorized(CategoryDuplicate, &apis.FieldError{
+		Message: fmt.Sprintf("duplicate Argo CD application name detected, the generated applications would overwrite each other: %s", name),
+		Paths:   paths,
+	})
+}
+
+func inconsistentGitTypeError(gitType, serviceURL string, paths []string) *categorizedError {
+	return categorized(CategoryGitType, &apis.FieldError{
 		Message: fmt.Sprintf("service URL must be a %s repository: %v", gitType, serviceURL),
 		Paths:   paths,
+	})
+}
+
+func gitOpsConfigRepoError(repo string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("the config_repo is the GitOps repository: %s", repo),
+		Details: "an application config_repo cannot refer back to the GitOps repository",
+		Paths:   paths,
+	}
+}
+
+func sameConfigNamespaceError(name string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("the ArgoCD namespace and the pipelines name are both %q", name),
+		Details: "ArgoCD and the pipelines must be deployed to separate namespaces",
+		Paths:   paths,
 	}
 }
 
@@ -306,20 +1895,89 @@ func addQuotes(items ...string) []string {
 	return quotes
 }
 
-func checkDuplicate(field, path string, checkMap map[string]bool) error {
-	_, ok := checkMap[path]
-	if ok {
-		return duplicateFieldsError([]string{field}, []string{path})
+// checkDuplicate records key in the checkMap, and returns an error for the path
+// if it has already been recorded.
+func (vv *validateVisitor) checkDuplicate(field, key, path string, checkMap map[string]string) error {
+	if _, ok := checkMap[key]; !ok {
+		checkMap[key] = path
+		return nil
 	}
-	checkMap[path] = true
-	return nil
+	return duplicateFieldsError([]string{field}, []string{path})
 }
 
-func checkDuplicateService(field, path, relativePath string, checkMap map[string]bool) error {
-	_, ok := checkMap[relativePath]
-	if ok {
-		return duplicateFieldsError([]string{field}, []string{path})
+// checkDuplicateService is the same as checkDuplicate for the service names in
+// an environment, but when the services are in different applications, the
+// error has the paths of both services.
+//
+// The services are compared by their name-based paths from recordPath.
+func (vv *validateVisitor) checkDuplicateService(name, key, namePath, path string) error {
+	previous, ok := vv.serviceNames[key]
+	if !ok {
+		vv.serviceNames[key] = namePath
+		return nil
 	}
-	checkMap[relativePath] = true
+	if previous != namePath {
+		return duplicateFieldsError([]string{name}, append(vv.reportedPaths([]string{previous}), path))
+	}
+	return duplicateFieldsError([]string{name}, []string{path})
+}
+
+// nameEntry records the manifest in a batch, and the path, where a name was
+// first seen.
+type nameEntry struct {
+	source int
+	path   string
+}
+
+func (e nameEntry) qualifiedPath() string {
+	return fmt.Sprintf("manifests[%d].%s", e.source, e.path)
+}
+
+// batchNames records the environments, applications and services of the
+// manifests in a batch, and reports those that are also in an earlier
+// manifest, the duplicates within a manifest are reported when it is
+// validated.
+type batchNames struct {
+	// source is the index of the manifest being visited.
+	source int
+	seen   map[string]nameEntry
+	errs   []error
+}
+
+func (b *batchNames) Environment(env *Environment) error {
+	path := yamlPath(PathForEnvironment(env))
+	b.add(env.Name, path, path)
+	return nil
+}
+
+func (b *batchNames) Application(env *Environment, app *Application) error {
+	path := yamlPath(PathForApplication(env, app))
+	b.add(app.Name, path, path)
+	return nil
+}
+
+func (b *batchNames) Service(app *Application, env *Environment, svc *Service) error {
+	// Services are unique within an environment, regardless of their
+	// application.
+	b.add(svc.Name, yamlJoin("services", env.Name, svc.Name), yamlPath(PathForService(app, env, svc.Name)))
 	return nil
 }
+
+func (b *batchNames) add(field, key, path string) {
+	previous, ok := b.seen[key]
+	if !ok {
+		b.seen[key] = nameEntry{source: b.source, path: path}
+		return
+	}
+	if previous.source != b.source {
+		current := nameEntry{source: b.source, path: path}
+		b.errs = append(b.errs, duplicateFieldsError([]string{field}, []string{previous.qualifiedPath(), current.qualifiedPath()}))
+	}
+}
+
+func viaManifest(err error, index int) error {
+	if fe, ok := asFieldError(err); ok {
+		return withCategoryOf(err, fe.ViaFieldIndex("manifests", index))
+	}
+	return fmt.Errorf("manifests[%d]: %w", index, err)
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"sort"
+	"sync"
+)
+
+// WithConcurrency validates up to workers environments concurrently, the
+// errors and warnings are the same, and in the same order, as when the
+// environments are validated one at a time.
+func WithConcurrency(workers int) ValidateOption {
+	return func(vv *validateVisitor) {
+		vv.workers = workers
+	}
+}
+
+// deferredCheck is a check that was recorded by a forked visitor, at is the
+// number of errors that preceded it.
+type deferredCheck struct {
+	at    int
+	check func(*validateVisitor) error
+}
+
+// shared makes a check that uses the state shared between environments, or
+// defers it until the results are merged, if the visitor is forked.
+func (vv *validateVisitor) shared(check func(*validateVisitor) error) {
+	if vv.forked {
+		vv.deferred = append(vv.deferred, deferredCheck{at: len(vv.errs), check: check})
+		return
+	}
+	if err := check(vv); err != nil {
+		vv.errs = append(vv.errs, err)
+	}
+}
+
+// fork returns a visitor for validating a single environment, with the
+// configuration of this visitor, and empty results.
+func (vv *validateVisitor) fork() *validateVisitor {
+	f := *vv
+	f.walkState = vv.walkState.empty()
+	f.forked = true
+	f.cache = nil
+	return &f
+}
+
+// walkConcurrently visits the environments of the manifest in the same order
+// as Walk, with a forked visitor for each environment, and then merges the
+// results in order.
+//
+// The forked visitors of the environments that are in the cache are merged
+// without visiting the environments again.
+func (vv *validateVisitor) walkConcurrently(m *Manifest) {
+	defaults := m.defaultPipelines()
+	sort.Sort(byName(m.Environments))
+	if err := vv.limits.check(m); err != nil {
+		vv.errs = append(vv.errs, err)
+		return
+	}
+	forks := make([]*validateVisitor, len(m.Environments))
+	resolved := make([]*Environment, len(m.Environments))
+	keys := make([]cacheKey, len(m.Environments))
+	for i, env := range m.Environments {
+		resolved[i] = withDefaultPipelines(env, defaults)
+		if vv.cache != nil {
+			keys[i] = vv.cache.key(m.Config, resolved[i])
+			forks[i] = vv.cache.get(keys[i])
+		}
+	}
+	envs := make(chan int)
+	var wg sync.WaitGroup
+	workers := vv.workers
+	if workers < 1 {
+		workers = 1
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range envs {
+				env := resolved[i]
+				if vv.cache != nil {
+					// The deferred checks of cached visitors refer to the
+					// environment, so they must not see later changes to it.
+					env = copyEnvironment(env)
+				}
+				f := vv.fork()
+				// The limits have been checked, and the visitor methods do not
+				// return errors.
+				_ = f.walk(&Manifest{Environments: []*Environment{env}}, f)
+				forks[i] = f
+			}
+		}()
+	}
+	for i := range m.Environments {
+		if forks[i] == nil {
+			envs <- i
+		}
+	}
+	close(envs)
+	wg.Wait()
+
+	for i, f := range forks {
+		vv.merge(f)
+		if vv.cache != nil {
+			vv.cache.put(keys[i], f)
+		}
+	}
+}
+
+// merge adds the results from a forked visitor, making the deferred checks in
+// the order that they were recorded.
+func (vv *validateVisitor) merge(f *validateVisitor) {
+	// The deferred checks report the paths of the environment.
+	for namePath, path := range f.indexedPaths {
+		vv.recordPath(namePath, path)
+	}
+	next := 0
+	check := func(before int) {
+		for ; next < len(f.deferred) && f.deferred[next].at <= before; next++ {
+			if err := f.deferred[next].check(vv); err != nil {
+				vv.errs = append(vv.errs, err)
+			}
+		}
+	}
+	for i, err := range f.errs {
+		check(i)
+		vv.errs = append(vv.errs, err)
+	}
+	check(len(f.errs))
+
+	vv.walkState.merge(&f.walkState)
+}
+
+// empty returns a walkState with empty values for the fields of this
+// walkState, the optional maps that are nil e.g. for the options that are not
+// enabled, are nil in the returned walkState.
+func (ws *walkState) empty() walkState {
+	e := walkState{
+		errs:         []error{},
+		warnings:     []string{},
+		envNames:     map[string]string{},
+		appNames:     map[string]string{},
+		serviceNames: map[string]string{},
+		serviceURLs:  sourceRepositories{},
+		foreignURLs:  map[string]bool{},
+		configRepos:  map[string][]string{},
+		usedBindings: map[string]bool{},
+
+		environmentBindings: map[string]map[string]bool{},
+
+		argoCDNames:    map[string][]string{},
+		webhookSecrets: map[string][]string{},
+		namespaces:     map[string][]string{},
+		promotions:     map[string]promotion{},
+
+		clusterNamespaces: map[string]string{},
+
+		environments:        []string{},
+		serviceEnvironments: map[string][]string{},
+	}
+	if ws.indexedPaths != nil {
+		e.indexedPaths = map[string]string{}
+	}
+	if ws.truncatedNames != nil {
+		e.truncatedNames = map[string]string{}
+	}
+	if ws.truncatedPaths != nil {
+		e.truncatedPaths = map[string]truncatedEntry{}
+	}
+	if ws.foldedNames != nil {
+		e.foldedNames = map[string][]foldedName{}
+	}
+	return e
+}
+
+// merge adds the results of the other walkState to this one, the errors, the
+// indexed paths and the deferred checks are merged by validateVisitor.merge.
+//
+// The names, the cluster namespaces and the truncated paths are not merged,
+// they are recorded by the checks of the state shared between environments,
+// which are made on this walkState, or only used within an environment.
+func (ws *walkState) merge(other *walkState) {
+	ws.warnings = append(ws.warnings, other.warnings...)
+	for url, paths := range other.serviceURLs {
+		ws.serviceURLs[url] = append(ws.serviceURLs[url], paths...)
+	}
+	for url := range other.foreignURLs {
+		ws.foreignURLs[url] = true
+	}
+	for repo, paths := range other.configRepos {
+		ws.configRepos[repo] = append(ws.configRepos[repo], paths...)
+	}
+	for name := range other.usedBindings {
+		ws.usedBindings[name] = true
+	}
+	// The bindings and promotions are recorded by environment name, and the
+	// environments are merged in the order that they are visited, so a later
+	// environment with the same name replaces them, as it does when the
+	// environments are visited one at a time.
+	for env, bindings := range other.environmentBindings {
+		ws.environmentBindings[env] = bindings
+	}
+	ws.serviceBindings = append(ws.serviceBindings, other.serviceBindings...)
+	ws.environments = append(ws.environments, other.environments...)
+	for name, envs := range other.serviceEnvironments {
+		ws.serviceEnvironments[name] = append(ws.serviceEnvironments[name], envs...)
+	}
+	ws.webhookPaths = append(ws.webhookPaths, other.webhookPaths...)
+	for name, paths := range other.argoCDNames {
+		ws.argoCDNames[name] = append(ws.argoCDNames[name], paths...)
+	}
+	for secret, paths := range other.webhookSecrets {
+		ws.webhookSecrets[secret] = append(ws.webhookSecrets[secret], paths...)
+	}
+	ws.applications += other.applications
+	for namespace, paths := range other.namespaces {
+		ws.namespaces[namespace] = append(ws.namespaces[namespace], paths...)
+	}
+	for name, p := range other.promotions {
+		ws.promotions[name] = p
+	}
+	// The truncated name of a service name is always the same.
+	for name, truncated := range other.truncatedNames {
+		ws.truncatedNames[name] = truncated
+	}
+	for key, names := range other.foldedNames {
+		ws.foldedNames[key] = append(ws.foldedNames[key], names...)
+	}
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestValidateWithConcurrency(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := [][]ValidateOption{
+		nil,
+		{WithGlobalServiceUniqueness()},
+		{WithServiceNameTruncation()},
+	}
+
+	for _, f := range files {
+		for i, opts := range options {
+			t.Run(fmt.Sprintf("%s/%d", filepath.Base(f), i), func(rt *testing.T) {
+				m, err := ParseFile(ioutils.NewFilesystem(), f)
+				if err != nil {
+					rt.Skipf("failed to parse file: %v", err)
+				}
+				assertSameValidation(rt, m, opts)
+			})
+		}
+	}
+}
+
+func TestValidateLargeManifestWithConcurrency(t *testing.T) {
+	m := &Manifest{GitOpsURL: "https://github.com/org/gitops.git"}
+	for e := 0; e < 20; e++ {
+		env := &Environment{Name: fmt.Sprintf("env-%d", e%18)}
+		for a := 0; a < 5; a++ {
+			app := &Application{Name: fmt.Sprintf("app-%d", a)}
+			for s := 0; s < 5; s++ {
+				app.Services = append(app.Services, &Service{
+					Name:      fmt.Sprintf("service-%d", (a*5+s)%23),
+					SourceURL: fmt.Sprintf("https://github.com/org/service-%d-%d.git", e%3, a*5+s),
+				})
+			}
+			env.Apps = append(env.Apps, app)
+		}
+		m.Environments = append(m.Environments, env)
+	}
+
+	assertSameValidation(t, m, nil)
+	assertSameValidation(t, m, []ValidateOption{WithGlobalServiceUniqueness()})
+}
+
+// assertSameValidation fails if validating the manifest concurrently returns
+// different errors, warnings or index to validating it serially.
+func assertSameValidation(t *testing.T, m *Manifest, opts []ValidateOption) {
+	t.Helper()
+	wantErr, wantWarnings := m.ValidateWithWarnings(opts...)
+	gotErr, gotWarnings := m.ValidateWithWarnings(append(opts, WithConcurrency(4))...)
+	if diff := cmp.Diff(fmt.Sprint(wantErr), fmt.Sprint(gotErr)); diff != "" {
+		t.Fatalf("errors did not match:\n%s", diff)
+	}
+	if diff := cmp.Diff(wantWarnings, gotWarnings); diff != "" {
+		t.Fatalf("warnings did not match:\n%s", diff)
+	}
+	if wantErr != nil {
+		return
+	}
+	want, err := m.Index(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := m.Index(append(opts, WithConcurrency(4))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("index did not match:\n%s", diff)
+	}
+}
+
+func TestWalkStateFieldsAreForkedAndMerged(t *testing.T) {
+	// Every field of the walkState must be set here, so that adding a field
+	// fails this test until it is handled by empty and merge.
+	ws := walkState{
+		errs:                []error{errors.New("failed")},
+		warnings:            []string{"warning"},
+		indexedPaths:        map[string]string{"environments.dev": "environments[0]"},
+		envNames:            map[string]string{"dev": "environments.dev"},
+		appNames:            map[string]string{"app": "environments.dev.apps.app"},
+		serviceNames:        map[string]string{"svc": "environments.dev.apps.app.services.svc"},
+		serviceURLs:         sourceRepositories{"github.com/org/svc": {"environments.dev.apps.app.services.svc"}},
+		foreignURLs:         map[string]bool{"github.com/org/svc": true},
+		configRepos:         map[string][]string{"github.com/org/config": {"environments.dev.apps.app"}},
+		clusterNamespaces:   map[string]string{"cluster dev": "environments.dev"},
+		usedBindings:        map[string]bool{"binding": true},
+		environmentBindings: map[string]map[string]bool{"dev": {"binding": true}},
+		serviceBindings:     []bindingRef{{env: "dev", name: "binding", path: "environments.dev"}},
+		environments:        []string{"dev"},
+		serviceEnvironments: map[string][]string{"svc": {"dev"}},
+		webhookPaths:        []string{"environments.dev.apps.app.services.svc.webhook"},
+		argoCDNames:         map[string][]string{"dev-app": {"environments.dev.apps.app"}},
+		webhookSecrets:      map[string][]string{"cicd/secret": {"environments.dev.apps.app.services.svc"}},
+		applications:        1,
+		namespaces:          map[string][]string{"dev": {"environments.dev"}},
+		promotions:          map[string]promotion{"dev": {to: "stage", path: "environments.dev"}},
+		truncatedNames:      map[string]string{"svc": "svc"},
+		foldedNames:         map[string][]foldedName{"svc": {{name: "svc", path: "environments.dev"}}},
+		truncatedPaths:      map[string]truncatedEntry{"svc": {name: "svc", path: "environments.dev"}},
+		deferred:            []deferredCheck{{at: 0}},
+	}
+	// The fields that are merged by validateVisitor.merge, or are not merged.
+	notMerged := map[string]bool{
+		"errs": true, "indexedPaths": true, "deferred": true,
+		"envNames": true, "appNames": true, "serviceNames": true,
+		"clusterNamespaces": true, "truncatedPaths": true,
+	}
+
+	empty := ws.empty()
+	merged := ws.empty()
+	merged.merge(&ws)
+
+	typ := reflect.TypeOf(ws)
+	for i := 0; i < typ.NumField(); i++ {
+		name := typ.Field(i).Name
+		if isZeroField(reflect.ValueOf(ws).Field(i)) {
+			t.Errorf("walkState field %s is not set by the test", name)
+			continue
+		}
+		e := reflect.ValueOf(empty).Field(i)
+		if !isZeroField(e) || (e.Kind() == reflect.Map && e.IsNil()) {
+			t.Errorf("walkState field %s is not an empty value in the forked state", name)
+		}
+		if !notMerged[name] && isZeroField(reflect.ValueOf(merged).Field(i)) {
+			t.Errorf("walkState field %s is not merged", name)
+		}
+	}
+}
+
+// isZeroField returns true if the field has no values.
+func isZeroField(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice:
+		return v.Len() == 0
+	case reflect.Int:
+		return v.Int() == 0
+	}
+	panic(fmt.Sprintf("unexpected field kind %s", v.Kind()))
+}
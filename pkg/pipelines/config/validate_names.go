@@ -0,0 +1,168 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"knative.dev/pkg/apis"
+)
+
+// NamePattern is a naming convention that names must follow, in addition to
+// the name policy of the manifest, the empty fields are not checked.
+type NamePattern struct {
+	// Prefix is the prefix that names must start with e.g. "team-a-".
+	Prefix string
+	// Suffix is the suffix that names must end with.
+	Suffix string
+	// Regexp is the regular expression that names must match.
+	Regexp *regexp.Regexp
+}
+
+// WithEnvironmentNamePattern requires environment names to follow the pattern.
+func WithEnvironmentNamePattern(p NamePattern) ValidateOption {
+	return func(vv *validateVisitor) {
+		vv.envNamePattern = &p
+	}
+}
+
+// WithApplicationNamePattern requires application names to follow the pattern.
+func WithApplicationNamePattern(p NamePattern) ValidateOption {
+	return func(vv *validateVisitor) {
+		vv.appNamePattern = &p
+	}
+}
+
+// WithServiceNamePattern requires service names to follow the pattern.
+func WithServiceNamePattern(p NamePattern) ValidateOption {
+	return func(vv *validateVisitor) {
+		vv.serviceNamePattern = &p
+	}
+}
+
+// WithCaseInsensitiveNames also reports the names of the environments,
+// applications and services, and the source URLs of the services, that differ
+// only in case, as people are likely to treat them as the same. By default,
+// names are compared in the same way as Kubernetes, where case is significant.
+func WithCaseInsensitiveNames() ValidateOption {
+	return func(vv *validateVisitor) {
+		vv.foldedNames = map[string][]foldedName{}
+	}
+}
+
+// sourceURLScope is the scope of the source URLs of the services, which are
+// compared across the manifest.
+const sourceURLScope = "source_url"
+
+// foldedName is a name, or URL, and the path that it is used at.
+type foldedName struct {
+	name, path string
+}
+
+// addFoldedName records the name in the scope, if names are compared without
+// case.
+func (vv *validateVisitor) addFoldedName(scope, name, path string) {
+	if vv.foldedNames == nil {
+		return
+	}
+	key := scope + "/" + strings.ToLower(name)
+	vv.foldedNames[key] = append(vv.foldedNames[key], foldedName{name: name, path: path})
+}
+
+// validateFoldedNames reports the names that are the same in a scope when
+// compared without case, names that are exactly the same are reported as
+// duplicates by the other checks.
+func (vv *validateVisitor) validateFoldedNames() []error {
+	if vv.foldedNames == nil {
+		return nil
+	}
+	for url, paths := range vv.serviceURLs {
+		for _, path := range paths {
+			vv.addFoldedName(sourceURLScope, url, path)
+		}
+	}
+	keys := []string{}
+	for key := range vv.foldedNames {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	errs := []error{}
+	for _, key := range keys {
+		names, paths := []string{}, []string{}
+		seenNames, seenPaths := map[string]bool{}, map[string]bool{}
+		for _, n := range vv.foldedNames[key] {
+			if !seenNames[n.name] {
+				seenNames[n.name] = true
+				names = append(names, n.name)
+			}
+			if !seenPaths[n.path] {
+				seenPaths[n.path] = true
+				paths = append(paths, n.path)
+			}
+		}
+		if len(names) < 2 {
+			continue
+		}
+		sort.Strings(names)
+		sort.Strings(paths)
+		kind := "names"
+		if strings.HasPrefix(key, sourceURLScope+"/") {
+			kind = "source URLs"
+		}
+		errs = append(errs, caseInsensitiveDuplicateError(kind, names, paths))
+	}
+	return errs
+}
+
+// matches returns true if the name follows the pattern.
+func (p *NamePattern) matches(name string) bool {
+	if !strings.HasPrefix(name, p.Prefix) || !strings.HasSuffix(name, p.Suffix) {
+		return false
+	}
+	return p.Regexp == nil || p.Regexp.MatchString(name)
+}
+
+// String describes the names that follow the pattern.
+func (p *NamePattern) String() string {
+	parts := []string{}
+	if p.Prefix != "" {
+		parts = append(parts, fmt.Sprintf("start with %q", p.Prefix))
+	}
+	if p.Suffix != "" {
+		parts = append(parts, fmt.Sprintf("end with %q", p.Suffix))
+	}
+	if p.Regexp != nil {
+		parts = append(parts, fmt.Sprintf("match %q", p.Regexp.String()))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// validateNamePattern checks that the name follows the pattern, if there is
+// one, the name policy is checked separately by validateName.
+func validateNamePattern(name, path string, p *NamePattern) error {
+	if p == nil || p.matches(name) {
+		return nil
+	}
+	return namePatternError(name, p, []string{path})
+}
+
+func namePatternError(name string, p *NamePattern, paths []string) *categorizedError {
+	return categorized(CategoryName, &apis.FieldError{
+		Message: fmt.Sprintf("name %q does not follow the naming convention", name),
+		Details: fmt.Sprintf("the name must %s", p),
+		Paths:   paths,
+	})
+}
+
+func caseInsensitiveDuplicateError(kind string, names, paths []string) *categorizedError {
+	quoted := []string{}
+	for _, name := range names {
+		quoted = append(quoted, fmt.Sprintf("%q", name))
+	}
+	return categorized(CategoryDuplicate, &apis.FieldError{
+		Message: fmt.Sprintf("duplicate %s ignoring case: %s", kind, strings.Join(quoted, ", ")),
+		Details: fmt.Sprintf("the %s differ only in case, and are likely to be mistaken for each other", kind),
+		Paths:   paths,
+	})
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/mkmik/multierror"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestValidateWithNamePatterns(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/name_patterns.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	envPattern := NamePattern{Prefix: "team-a-"}
+	appPattern := NamePattern{Regexp: regexp.MustCompile(`^app-`)}
+	svcPattern := NamePattern{Suffix: "-svc"}
+
+	err = m.Validate(
+		WithEnvironmentNamePattern(envPattern),
+		WithApplicationNamePattern(appPattern),
+		WithServiceNamePattern(svcPattern))
+	want := multierror.Join([]error{
+		namePatternError("staging", &envPattern, []string{"environments.staging"}),
+		namePatternError("taxi", &appPattern, []string{"environments.staging.apps.taxi"}),
+		namePatternError("taxi", &svcPattern, []string{"environments.staging.apps.taxi.services.taxi"}),
+		invalidNameError("Meter-svc", DNS1035Error, []string{"environments.team-a-dev.apps.app-taxi.services.Meter-svc"}),
+	})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNamePatternString(t *testing.T) {
+	patternTests := []struct {
+		pattern NamePattern
+		want    string
+	}{
+		{NamePattern{Prefix: "team-a-"}, `start with "team-a-"`},
+		{NamePattern{Prefix: "team-a-", Suffix: "-svc"}, `start with "team-a-" and end with "-svc"`},
+		{NamePattern{Suffix: "-svc", Regexp: regexp.MustCompile(`^[a-z]+-svc$`)}, `end with "-svc" and match "^[a-z]+-svc$"`},
+	}
+
+	for _, tt := range patternTests {
+		if got := tt.pattern.String(); got != tt.want {
+			t.Errorf("String() got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestValidateWithCaseInsensitiveNames(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/case_insensitive_names.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+
+	err = m.Validate(WithCaseInsensitiveNames())
+	want := multierror.Join([]error{
+		caseInsensitiveDuplicateError("names", []string{"CICD", "cicd"}, []string{"config.cicd", "environments.CICD"}),
+		invalidNameError("CICD", DNS1035Error, []string{"environments.CICD"}),
+		caseInsensitiveDuplicateError("names", []string{"App-1", "app-1"},
+			[]string{"environments.development.apps.App-1", "environments.development.apps.app-1"}),
+		invalidNameError("App-1", DNS1035Error, []string{"environments.development.apps.App-1"}),
+		caseInsensitiveDuplicateError("names", []string{"Service-1", "service-1"},
+			[]string{"environments.development.apps.App-1.services.Service-1", "environments.development.apps.app-1.services.service-1"}),
+		caseInsensitiveDuplicateError("source URLs", []string{"https://github.com/org/Service-1", "https://github.com/org/service-1"},
+			[]string{"environments.development.apps.App-1.services.Service-1", "environments.development.apps.app-1.services.service-1"}),
+		invalidNameError("Service-1", DNS1035Error, []string{"environments.development.apps.App-1.services.Service-1"}),
+	})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateIsCaseSensitiveByDefault(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/case_insensitive_names.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+
+	err = m.Validate()
+	want := multierror.Join([]error{
+		invalidNameError("CICD", DNS1035Error, []string{"environments.CICD"}),
+		invalidNameError("App-1", DNS1035Error, []string{"environments.development.apps.App-1"}),
+		invalidNameError("Service-1", DNS1035Error, []string{"environments.development.apps.App-1.services.Service-1"}),
+	})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,509 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	gopath "path"
+	"sort"
+	"strings"
+
+	goscm "github.com/jenkins-x/go-scm/scm"
+	"github.com/mkmik/multierror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/yaml"
+)
+
+// ValidateReachable checks that the source repository of each service exists,
+// and can be read with the credentials of the client.
+//
+// This makes an API call for each distinct repository, and so isn't part of
+// Validate.
+func (m *Manifest) ValidateReachable(ctx context.Context, client *goscm.Client) error {
+	repos := map[string][]string{}
+	for rawURL, paths := range m.SourceRepositories() {
+		repo := normalizeGitURL(rawURL)
+		repos[repo] = append(repos[repo], paths...)
+	}
+	names := []string{}
+	for repo := range repos {
+		names = append(names, repo)
+	}
+	sort.Strings(names)
+
+	errs := []error{}
+	for _, repo := range names {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		_, res, err := client.Repositories.Find(ctx, repoFullName(repo))
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		status := 0
+		if res != nil {
+			status = res.Status
+		}
+		if status == 0 && err == goscm.ErrNotFound {
+			status = http.StatusNotFound
+		}
+		sort.Strings(repos[repo])
+		errs = append(errs, unreachableRepositoryError(repo, status, err, repos[repo]))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return multierror.Join(errs)
+}
+
+// ValidateWebhookSecrets checks that the webhook secret of each service exists
+// in the cluster, and that it has a non-empty value for the key that the
+// EventListener reads the webhook secret from, either the configured key, or
+// the default key.
+//
+// This queries the cluster, and so isn't part of Validate.
+func (m *Manifest) ValidateWebhookSecrets(ctx context.Context, kubeClient kubernetes.Interface) error {
+	sv := &webhookSecretsVisitor{kubeClient: kubeClient, secrets: map[Secret]error{}}
+	if err := m.WalkContext(ctx, sv); err != nil {
+		return err
+	}
+	return joinErrors(sv.errs)
+}
+
+type webhookSecretsVisitor struct {
+	kubeClient kubernetes.Interface
+	// secrets caches the result of checking each secret, as services can
+	// share a secret.
+	secrets map[Secret]error
+	errs    []error
+}
+
+func (sv *webhookSecretsVisitor) Service(app *Application, env *Environment, svc *Service) error {
+	if svc.Webhook == nil || svc.Webhook.Secret == nil {
+		return nil
+	}
+	ref := *svc.Webhook.Secret
+	checkErr, ok := sv.secrets[ref]
+	if !ok {
+		checkErr = sv.checkSecret(ref)
+		sv.secrets[ref] = checkErr
+	}
+	if checkErr != nil {
+		sv.errs = append(sv.errs, invalidWebhookSecretError(svc.Name, ref, checkErr.Error(), []string{yamlJoin(yamlPath(PathForService(app, env, svc.Name)), "webhook", "secret")}))
+	}
+	return nil
+}
+
+func (sv *webhookSecretsVisitor) checkSecret(ref Secret) error {
+	secret, err := sv.kubeClient.CoreV1().Secrets(ref.Namespace).Get(ref.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("secret does not exist")
+	}
+	if err != nil {
+		return err
+	}
+	value, ok := secret.Data[ref.SecretKey()]
+	if !ok {
+		return fmt.Errorf("secret does not have the key %q", ref.SecretKey())
+	}
+	if len(bytes.TrimSpace(value)) == 0 {
+		return fmt.Errorf("secret has an empty value for the key %q", ref.SecretKey())
+	}
+	return nil
+}
+
+// triggerBindingsResource is the resource of the Tekton TriggerBindings that
+// the pipelines reference.
+var triggerBindingsResource = schema.GroupVersionResource{
+	Group:    "triggers.tekton.dev",
+	Version:  "v1alpha1",
+	Resource: "triggerbindings",
+}
+
+// ValidateTriggerBindings checks that each TriggerBinding referenced by the
+// pipelines of the environments and services exists in the pipelines
+// namespace of the cluster.
+//
+// This queries the cluster, and so isn't part of Validate.
+func (m *Manifest) ValidateTriggerBindings(ctx context.Context, client dynamic.Interface) error {
+	pipelines := m.GetPipelinesConfig()
+	if pipelines == nil {
+		return nil
+	}
+	list, err := client.Resource(triggerBindingsResource).Namespace(pipelines.Name).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list the TriggerBindings in namespace %q: %w", pipelines.Name, err)
+	}
+	bv := &triggerBindingsVisitor{namespace: pipelines.Name, bindings: map[string]bool{}}
+	for _, item := range list.Items {
+		bv.bindings[item.GetName()] = true
+	}
+	if err := m.WalkContext(ctx, bv); err != nil {
+		return err
+	}
+	return joinErrors(bv.errs)
+}
+
+type triggerBindingsVisitor struct {
+	namespace string
+	// bindings are the names of the TriggerBindings in the namespace.
+	bindings map[string]bool
+	errs     []error
+}
+
+func (bv *triggerBindingsVisitor) Environment(env *Environment) error {
+	bv.checkPipelines(env.Pipelines, yamlPath(PathForEnvironment(env)))
+	return nil
+}
+
+func (bv *triggerBindingsVisitor) Service(app *Application, env *Environment, svc *Service) error {
+	bv.checkPipelines(svc.Pipelines, yamlPath(PathForService(app, env, svc.Name)))
+	return nil
+}
+
+func (bv *triggerBindingsVisitor) checkPipelines(pipelines *Pipelines, path string) {
+	if pipelines == nil || pipelines.Integration == nil {
+		return
+	}
+	seen := map[string]bool{}
+	for _, name := range pipelines.Integration.Bindings {
+		if name == "" || seen[name] || bv.bindings[name] {
+			continue
+		}
+		seen[name] = true
+		bv.errs = append(bv.errs, missingClusterBindingError(name, bv.namespace, []string{yamlJoin(path, "pipelines", "integration", "bindings")}))
+	}
+}
+
+var appProjectsResource = schema.GroupVersionResource{
+	Group:    "argoproj.io",
+	Version:  "v1alpha1",
+	Resource: "appprojects",
+}
+
+// ValidateArgoCDProjects checks that the Argo CD project of each environment,
+// and the project from the Argo CD config, exists as an AppProject in the Argo
+// CD namespace of the cluster. Environments without a project in the manifest
+// use the DefaultArgoCDProject, which is not checked.
+//
+// This queries the cluster, and so isn't part of Validate.
+func (m *Manifest) ValidateArgoCDProjects(ctx context.Context, client dynamic.Interface) error {
+	argoCD := m.GetArgoCDConfig()
+	if argoCD == nil {
+		return nil
+	}
+	list, err := client.Resource(appProjectsResource).Namespace(argoCD.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list the AppProjects in namespace %q: %w", argoCD.Namespace, err)
+	}
+	projects := map[string]bool{}
+	for _, item := range list.Items {
+		projects[item.GetName()] = true
+	}
+	pv := &argoCDProjectsVisitor{namespace: argoCD.Namespace, projects: projects}
+	if argoCD.Project != "" && !projects[argoCD.Project] {
+		pv.errs = append(pv.errs, missingArgoCDProjectError(argoCD.Project, argoCD.Namespace, []string{yamlJoin(yamlPath(PathForArgoCD()), "project")}))
+	}
+	if err := m.WalkContext(ctx, pv); err != nil {
+		return err
+	}
+	return joinErrors(pv.errs)
+}
+
+type argoCDProjectsVisitor struct {
+	namespace string
+	// projects are the names of the AppProjects in the namespace.
+	projects map[string]bool
+	errs     []error
+}
+
+func (pv *argoCDProjectsVisitor) Environment(env *Environment) error {
+	if env.ArgoCDProject != "" && !pv.projects[env.ArgoCDProject] {
+		pv.errs = append(pv.errs, missingArgoCDProjectError(env.ArgoCDProject, pv.namespace, []string{yamlJoin(yamlPath(PathForEnvironment(env)), "argocd_project")}))
+	}
+	return nil
+}
+
+// ValidateConfigRepoPaths checks that each application config_repo path exists
+// at the target_revision of the repository, or if the path is a glob pattern,
+// that it matches at least one file or directory.
+//
+// If the path is a kustomization, the resources and bases that it references
+// must exist, and be within the repository, remote references are not
+// checked.
+//
+// This makes API calls to list the contents of the repositories, and so isn't
+// part of Validate.
+func (m *Manifest) ValidateConfigRepoPaths(ctx context.Context, client *goscm.Client) error {
+	cv := &configRepoPathsVisitor{client: client}
+	if err := m.WalkContext(ctx, cv); err != nil {
+		return err
+	}
+	return joinErrors(cv.errs)
+}
+
+// joinErrors returns nil if there are no errors, a single error unchanged, and
+// otherwise the errors as a multi-error, in the same way as WalkUntil.
+func joinErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return multierror.Join(errs)
+}
+
+type configRepoPathsVisitor struct {
+	client *goscm.Client
+	errs   []error
+}
+
+func (cv *configRepoPathsVisitor) ApplicationContext(ctx context.Context, env *Environment, app *Application) error {
+	repo := app.ConfigRepo
+	if repo == nil || repo.URL == "" || repo.Path == "" {
+		return nil
+	}
+	if isGlob(repo.Path) {
+		if _, err := gopath.Match(repo.Path, ""); err != nil {
+			return nil
+		}
+	}
+	name := repoFullName(normalizeGitURL(repo.URL))
+	paths := []string{yamlJoin(yamlPath(PathForApplication(env, app)), "config_repo", "path")}
+	if !isGlob(repo.Path) {
+		exists, err := cv.exists(ctx, name, repo.TargetRevision, repo.Path)
+		if err != nil {
+			return fmt.Errorf("failed to check the config_repo path %q in %s: %w", repo.Path, repo.URL, err)
+		}
+		if !exists {
+			cv.errs = append(cv.errs, missingConfigRepoPathError(repo.URL, repo.TargetRevision, repo.Path, paths))
+			return nil
+		}
+		if err := cv.checkKustomization(ctx, name, repo, paths); err != nil {
+			return fmt.Errorf("failed to check the kustomization at %q in %s: %w", repo.Path, repo.URL, err)
+		}
+		return nil
+	}
+	matches, err := cv.glob(ctx, name, repo.TargetRevision, repo.Path)
+	if err != nil {
+		return fmt.Errorf("failed to check the config_repo path %q in %s: %w", repo.Path, repo.URL, err)
+	}
+	if len(matches) == 0 {
+		cv.errs = append(cv.errs, noGlobMatchError(repo.URL, repo.Path, paths))
+	}
+	return nil
+}
+
+// kustomization is the part of a kustomization file that references other
+// files.
+type kustomization struct {
+	Resources []string `json:"resources,omitempty"`
+	Bases     []string `json:"bases,omitempty"`
+}
+
+// checkKustomization reads the kustomization at the config_repo path, if there
+// is one, and records an error for each resource or base that is outside the
+// repository, or does not exist.
+func (cv *configRepoPathsVisitor) checkKustomization(ctx context.Context, name string, repo *Repository, paths []string) error {
+	dir, content := cv.findKustomization(ctx, name, repo.TargetRevision, strings.Trim(repo.Path, "/"))
+	if content == nil {
+		return nil
+	}
+	var k kustomization
+	if err := yaml.Unmarshal(content.Data, &k); err != nil {
+		cv.errs = append(cv.errs, invalidKustomizationError(repo.URL, content.Path, err.Error(), paths))
+		return nil
+	}
+	for _, ref := range append(k.Resources, k.Bases...) {
+		if isRemoteResource(ref) {
+			continue
+		}
+		target := gopath.Join(dir, ref)
+		if target == ".." || strings.HasPrefix(target, "../") || gopath.IsAbs(ref) {
+			cv.errs = append(cv.errs, kustomizationOutsideRepoError(repo.URL, content.Path, ref, paths))
+			continue
+		}
+		exists, err := cv.exists(ctx, name, repo.TargetRevision, target)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			cv.errs = append(cv.errs, missingKustomizationResourceError(repo.URL, content.Path, ref, paths))
+		}
+	}
+	return nil
+}
+
+// findKustomization returns the directory of the kustomization at the path,
+// and its content, or nil if the path is a directory without a kustomization.
+func (cv *configRepoPathsVisitor) findKustomization(ctx context.Context, repo, ref, path string) (string, *goscm.Content) {
+	names := kustomizationFiles
+	dir := path
+	for _, name := range kustomizationFiles {
+		if gopath.Base(path) == name {
+			names, dir = []string{name}, gopath.Dir(path)
+		}
+	}
+	for _, name := range names {
+		if content, _, err := cv.client.Contents.Find(ctx, repo, gopath.Join(dir, name), ref); err == nil {
+			return dir, content
+		}
+	}
+	return dir, nil
+}
+
+// isRemoteResource returns true if the kustomization reference is a URL, or a
+// remote Git repository e.g. github.com/org/repo//path?ref=v1, rather than a
+// path in the repository.
+func isRemoteResource(ref string) bool {
+	return strings.Contains(ref, "://") || strings.HasPrefix(ref, "git@") ||
+		strings.Contains(ref, "//") || strings.Contains(ref, "?ref=")
+}
+
+// exists returns true if the path is a directory or a file in the repository,
+// and false if the path is not found, any other error is returned.
+//
+// A path that can't be listed may still be a file, so only the error from
+// finding the file is checked.
+func (cv *configRepoPathsVisitor) exists(ctx context.Context, repo, ref, path string) (bool, error) {
+	path = strings.Trim(path, "/")
+	if _, _, err := cv.client.Contents.List(ctx, repo, path, ref); err == nil {
+		return true, nil
+	}
+	_, res, err := cv.client.Contents.Find(ctx, repo, path, ref)
+	if isNotFound(res, err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// glob lists the directories in the repository one level at a time, returning
+// the paths that match the pattern.
+//
+// Directories that do not exist have no matches, any other error from listing
+// a directory is returned.
+func (cv *configRepoPathsVisitor) glob(ctx context.Context, repo, ref, pattern string) ([]string, error) {
+	segments := strings.Split(strings.Trim(pattern, "/"), "/")
+	candidates := []string{""}
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		matched := []string{}
+		for _, dir := range candidates {
+			entries, res, err := cv.client.Contents.List(ctx, repo, dir, ref)
+			if isNotFound(res, err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			for _, entry := range entries {
+				if ok, _ := gopath.Match(segment, entry.Name); !ok {
+					continue
+				}
+				if last || entry.Type == "dir" {
+					matched = append(matched, gopath.Join(dir, entry.Name))
+				}
+			}
+		}
+		candidates = matched
+	}
+	return candidates, nil
+}
+
+// isNotFound returns true if the response and error from the Git hosting
+// service are for a repository or path that does not exist.
+func isNotFound(res *goscm.Response, err error) bool {
+	if err == nil {
+		return false
+	}
+	return err == goscm.ErrNotFound || (res != nil && res.Status == http.StatusNotFound)
+}
+
+// repoFullName returns the path of a normalized repository URL, which is the
+// name that the Git hosting services use to identify the repository.
+func repoFullName(repo string) string {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) < 2 {
+		return repo
+	}
+	return parts[1]
+}
+
+func unreachableRepositoryError(repo string, status int, err error, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("repository %s is not reachable: HTTP status %d", repo, status),
+		Details: err.Error(),
+		Paths:   paths,
+	}
+}
+
+func invalidWebhookSecretError(service string, ref Secret, details string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid webhook secret %s/%s for service %q", ref.Namespace, ref.Name, service),
+		Details: details,
+		Paths:   paths,
+	}
+}
+
+func missingConfigRepoPathError(repoURL, ref, path string, paths []string) *apis.FieldError {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	return &apis.FieldError{
+		Message: fmt.Sprintf("config_repo path %q does not exist at %q in %s", path, ref, repoURL),
+		Paths:   paths,
+	}
+}
+
+func missingClusterBindingError(name, namespace string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("TriggerBinding %q does not exist in namespace %q", name, namespace),
+		Paths:   paths,
+	}
+}
+
+func missingArgoCDProjectError(project, namespace string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("AppProject %q does not exist in namespace %q", project, namespace),
+		Paths:   paths,
+	}
+}
+
+func invalidKustomizationError(repoURL, file, details string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid kustomization %q in %s", file, repoURL),
+		Details: details,
+		Paths:   paths,
+	}
+}
+
+func kustomizationOutsideRepoError(repoURL, file, resource string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("kustomization %q in %s references %q outside of the repository", file, repoURL, resource),
+		Paths:   paths,
+	}
+}
+
+func missingKustomizationResourceError(repoURL, file, resource string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("kustomization %q in %s references %q, which does not exist", file, repoURL, resource),
+		Paths:   paths,
+	}
+}
+
+func noGlobMatchError(repoURL, pattern string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("config_repo path %q does not match any files in %s", pattern, repoURL),
+		Paths:   paths,
+	}
+}
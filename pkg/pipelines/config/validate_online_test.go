@@ -0,0 +1,328 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	goscm "github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/mkmik/multierror"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestValidateReachable(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/reachable.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	client, data := fake.NewDefault()
+	data.Repositories = []*goscm.Repository{{FullName: "myproject/service-1"}}
+	repos := &countingRepositoryService{RepositoryService: client.Repositories, calls: map[string]int{}}
+	client.Repositories = repos
+
+	want := multierror.Join(
+		[]error{
+			unreachableRepositoryError("github.com/myproject/missing", 404, goscm.ErrNotFound, []string{
+				"environments.development.apps.my-app-1.services.service-2",
+				"environments.development.apps.my-app-2.services.service-3"}),
+		},
+	)
+	if err := matchMultiErrors(t, m.ValidateReachable(context.Background(), client), want); err != nil {
+		t.Fatal(err)
+	}
+	for name, calls := range repos.calls {
+		if calls != 1 {
+			t.Errorf("repository %s was requested %d times, want 1", name, calls)
+		}
+	}
+}
+
+func TestValidateReachableWithCancelledContext(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/reachable.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	client, _ := fake.NewDefault()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := m.ValidateReachable(ctx, client); err != context.Canceled {
+		t.Fatalf("got %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestValidateWebhookSecrets(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/webhook_secrets.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	kubeClient := fakekube.NewSimpleClientset(
+		makeSecret("cicd", "service-1-secret", map[string][]byte{"webhook-secret-key": []byte("testing")}),
+		makeSecret("cicd", "service-3-secret", map[string][]byte{"secret": []byte("testing")}),
+		makeSecret("cicd", "service-4-secret", map[string][]byte{"webhook-secret-2024": []byte("testing")}),
+		makeSecret("cicd", "service-5-secret", map[string][]byte{"webhook-secret-key": []byte("")}),
+		makeSecret("cicd", "service-6-secret", map[string][]byte{"webhook-secret-key": []byte(" \n")}),
+	)
+
+	want := multierror.Join(
+		[]error{
+			invalidWebhookSecretError("service-2", Secret{Name: "service-2-secret", Namespace: "cicd"}, "secret does not exist",
+				[]string{"environments.development.apps.my-app-1.services.service-2.webhook.secret"}),
+			invalidWebhookSecretError("service-3", Secret{Name: "service-3-secret", Namespace: "cicd"}, `secret does not have the key "webhook-secret-key"`,
+				[]string{"environments.development.apps.my-app-1.services.service-3.webhook.secret"}),
+			invalidWebhookSecretError("service-5", Secret{Name: "service-5-secret", Namespace: "cicd"}, `secret has an empty value for the key "webhook-secret-key"`,
+				[]string{"environments.development.apps.my-app-1.services.service-5.webhook.secret"}),
+			invalidWebhookSecretError("service-6", Secret{Name: "service-6-secret", Namespace: "cicd"}, `secret has an empty value for the key "webhook-secret-key"`,
+				[]string{"environments.development.apps.my-app-1.services.service-6.webhook.secret"}),
+		},
+	)
+	if err := matchMultiErrors(t, m.ValidateWebhookSecrets(context.Background(), kubeClient), want); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateConfigRepoPaths(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/config_repo_glob.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	client, data := fake.NewDefault()
+	data.ContentDir = "testdata/repos"
+
+	want := multierror.Join(
+		[]error{
+			noGlobMatchError("https://github.com/org/config.git", "environments/*/kustomization.yaml",
+				[]string{"environments.development.apps.app-2.config_repo.path"}),
+		},
+	)
+	if err := matchMultiErrors(t, m.ValidateConfigRepoPaths(context.Background(), client), want); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateConfigRepoPathsWithListError(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/config_repo_glob.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	client, data := fake.NewDefault()
+	data.ContentDir = "testdata/repos"
+	client.Contents = &failingContentService{ContentService: client.Contents, status: http.StatusTooManyRequests}
+
+	err = m.ValidateConfigRepoPaths(context.Background(), client)
+	want := `failed to check the config_repo path "overlays/*/kustomization.yaml" in https://github.com/org/config.git: HTTP status 429`
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v, want %q", err, want)
+	}
+}
+
+func TestValidateConfigRepoPathsAtRevision(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/config_repo_paths.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	client, data := fake.NewDefault()
+	data.ContentDir = "testdata/repos"
+	contents := &recordingContentService{ContentService: client.Contents, refs: map[string]string{}}
+	client.Contents = contents
+
+	want := multierror.Join(
+		[]error{
+			missingConfigRepoPathError("https://github.com/org/config.git", "feature/overlays", "environments/dev",
+				[]string{"environments.development.apps.app-2.config_repo.path"}),
+			missingConfigRepoPathError("https://github.com/org/config.git", "", "base/missing.yaml",
+				[]string{"environments.development.apps.app-4.config_repo.path"}),
+		},
+	)
+	if err := matchMultiErrors(t, m.ValidateConfigRepoPaths(context.Background(), client), want); err != nil {
+		t.Fatal(err)
+	}
+	if ref := contents.refs["overlays/dev"]; ref != "feature/overlays" {
+		t.Fatalf("path was listed at ref %q, want %q", ref, "feature/overlays")
+	}
+}
+
+func TestValidateConfigRepoPathsWithFindError(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/config_repo_paths.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	client, data := fake.NewDefault()
+	data.ContentDir = "testdata/repos"
+	client.Contents = &failingContentService{ContentService: client.Contents, status: http.StatusUnauthorized}
+
+	err = m.ValidateConfigRepoPaths(context.Background(), client)
+	want := `failed to check the config_repo path "overlays/dev" in https://github.com/org/config.git: HTTP status 401`
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v, want %q", err, want)
+	}
+}
+
+func TestValidateConfigRepoPathsKustomizations(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/kustomization_references.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	client, data := fake.NewDefault()
+	data.ContentDir = "testdata/repos"
+
+	want := multierror.Join(
+		[]error{
+			missingKustomizationResourceError("https://github.com/org/config.git", "overlays/broken/kustomization.yaml", "service.yaml",
+				[]string{"environments.development.apps.app-2.config_repo.path"}),
+			kustomizationOutsideRepoError("https://github.com/org/config.git", "overlays/broken/kustomization.yaml", "../../../outside",
+				[]string{"environments.development.apps.app-2.config_repo.path"}),
+		},
+	)
+	if err := matchMultiErrors(t, m.ValidateConfigRepoPaths(context.Background(), client), want); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateTriggerBindings(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/trigger_bindings.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	client := &fakeDynamicClient{
+		namespace: "cicd",
+		items:     []string{"dev-ci-binding", "dev-cd-binding"},
+	}
+
+	want := multierror.Join(
+		[]error{
+			missingClusterBindingError("service-1-binding", "cicd",
+				[]string{"environments.development.apps.app-1.services.service-1.pipelines.integration.bindings"}),
+			missingClusterBindingError("github-push-binding", "cicd",
+				[]string{"environments.development.pipelines.integration.bindings"}),
+		},
+	)
+	if err := matchMultiErrors(t, m.ValidateTriggerBindings(context.Background(), client), want); err != nil {
+		t.Fatal(err)
+	}
+	if client.resource != triggerBindingsResource {
+		t.Fatalf("listed resource %v, want %v", client.resource, triggerBindingsResource)
+	}
+}
+
+func makeSecret(ns, name string, data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+		},
+		Data: data,
+	}
+}
+
+type countingRepositoryService struct {
+	goscm.RepositoryService
+	calls map[string]int
+}
+
+func (c *countingRepositoryService) Find(ctx context.Context, name string) (*goscm.Repository, *goscm.Response, error) {
+	c.calls[name]++
+	return c.RepositoryService.Find(ctx, name)
+}
+
+type recordingContentService struct {
+	goscm.ContentService
+	refs  map[string]string
+	calls int
+}
+
+func (r *recordingContentService) Find(ctx context.Context, repo, path, ref string) (*goscm.Content, *goscm.Response, error) {
+	r.refs[path] = ref
+	r.calls++
+	return r.ContentService.Find(ctx, repo, path, ref)
+}
+
+func (r *recordingContentService) List(ctx context.Context, repo, path, ref string) ([]*goscm.FileEntry, *goscm.Response, error) {
+	r.refs[path] = ref
+	return r.ContentService.List(ctx, repo, path, ref)
+}
+
+// failingContentService fails every request with the HTTP status.
+type failingContentService struct {
+	goscm.ContentService
+	status int
+}
+
+func (f *failingContentService) Find(ctx context.Context, repo, path, ref string) (*goscm.Content, *goscm.Response, error) {
+	return nil, &goscm.Response{Status: f.status}, fmt.Errorf("HTTP status %d", f.status)
+}
+
+func (f *failingContentService) List(ctx context.Context, repo, path, ref string) ([]*goscm.FileEntry, *goscm.Response, error) {
+	return nil, &goscm.Response{Status: f.status}, fmt.Errorf("HTTP status %d", f.status)
+}
+
+// fakeDynamicClient lists the named items in a namespace, the other methods of
+// the dynamic client are not implemented.
+type fakeDynamicClient struct {
+	dynamic.NamespaceableResourceInterface
+	resource  schema.GroupVersionResource
+	namespace string
+	listed    string
+	items     []string
+}
+
+func (f *fakeDynamicClient) Resource(resource schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	f.resource = resource
+	return f
+}
+
+func (f *fakeDynamicClient) Namespace(ns string) dynamic.ResourceInterface {
+	f.listed = ns
+	return f
+}
+
+func (f *fakeDynamicClient) List(opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	list := &unstructured.UnstructuredList{}
+	if f.listed != f.namespace {
+		return list, nil
+	}
+	for _, name := range f.items {
+		item := unstructured.Unstructured{}
+		item.SetName(name)
+		list.Items = append(list.Items, item)
+	}
+	return list, nil
+}
+
+func TestValidateArgoCDProjects(t *testing.T) {
+	m := &Manifest{
+		Config: &Config{
+			ArgoCD: &ArgoCDConfig{Namespace: "argocd", Project: "team-a"},
+		},
+		Environments: []*Environment{
+			{Name: "development"},
+			{Name: "staging", ArgoCDProject: "staging"},
+			{Name: "production", ArgoCDProject: "production"},
+		},
+	}
+	client := &fakeDynamicClient{
+		namespace: "argocd",
+		items:     []string{"default", "staging"},
+	}
+
+	want := multierror.Join(
+		[]error{
+			missingArgoCDProjectError("team-a", "argocd", []string{"config.argocd.project"}),
+			missingArgoCDProjectError("production", "argocd", []string{"environments.production.argocd_project"}),
+		},
+	)
+	if err := matchMultiErrors(t, m.ValidateArgoCDProjects(context.Background(), client), want); err != nil {
+		t.Fatal(err)
+	}
+	if client.resource != appProjectsResource {
+		t.Fatalf("listed resource %v, want %v", client.resource, appProjectsResource)
+	}
+}
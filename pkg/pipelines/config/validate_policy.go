@@ -0,0 +1,199 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"knative.dev/pkg/apis"
+)
+
+// Severity is how a problem found by a validation rule is reported.
+type Severity string
+
+const (
+	// SeverityError reports the problem as an error.
+	SeverityError Severity = "error"
+	// SeverityWarn reports the problem as a warning from ValidateWithWarnings.
+	SeverityWarn Severity = "warn"
+	// SeverityOff does not report the problem.
+	SeverityOff Severity = "off"
+)
+
+// The identifiers of the validation rules that can be configured with a
+// ValidationPolicy.
+const (
+	// RuleMissingWebhook reports services with a source_url and no webhook,
+	// it is off by default.
+	RuleMissingWebhook = "service.missing-webhook"
+	// RuleDuplicateSource reports source URLs used by several services.
+	RuleDuplicateSource = "service.duplicate-source"
+	// RuleGitType reports services hosted by a different type of Git provider
+	// to the GitOps repository.
+	RuleGitType = "service.git-type"
+	// RuleUncheckedGitType reports services whose Git type can't be checked,
+	// it is a warning by default.
+	RuleUncheckedGitType = "service.unchecked-git-type"
+	// RuleNameTooLong reports service names that exceed the limit.
+	RuleNameTooLong = "name.too-long"
+	// RuleNameNearLimit reports service names that are close to the limit, it
+	// is a warning by default.
+	RuleNameNearLimit = "name.near-limit"
+	// RuleEnvironmentWithoutApps reports environments with no applications,
+	// it is a warning by default.
+	RuleEnvironmentWithoutApps = "environment.no-apps"
+	// RuleConfigRepoWithoutPipelines reports applications with a config_repo
+	// in an environment without pipelines, it is a warning by default.
+	RuleConfigRepoWithoutPipelines = "application.config-repo-without-pipelines"
+	// RuleApplicationWithoutSource reports applications without a config_repo
+	// where none of the services have a source_url, it is off by default.
+	RuleApplicationWithoutSource = "application.no-source"
+	// RuleTriggerNameTruncated reports services with a source_url whose
+	// trigger name is truncated, or otherwise changed, by the name sanitizer,
+	// it is a warning by default.
+	RuleTriggerNameTruncated = "service.trigger-name-truncated"
+	// RuleDuplicateWebhookSecret reports webhook secrets used by several
+	// services, unless the services set shared_webhook_secret.
+	RuleDuplicateWebhookSecret = "service.duplicate-webhook-secret"
+	// RuleWebhooksNotSupported reports services hosted by a Git provider that
+	// can't deliver webhooks, it is a warning by default.
+	RuleWebhooksNotSupported = "service.webhooks-not-supported"
+	// RuleUnsupportedWebhook reports services with a webhook that are hosted
+	// by a Git provider that can't deliver webhooks, these are reported
+	// instead of RuleWebhooksNotSupported.
+	RuleUnsupportedWebhook = "service.unsupported-webhook"
+	// RuleBindingCollision reports services with a binding that is also used
+	// by the environment with a different template, it is off by default, as
+	// bindings like the push binding are commonly shared by templates.
+	RuleBindingCollision = "service.binding-collision"
+	// RuleRedundantPipelines reports services with the same integration
+	// template and bindings as their environment, it is a warning by default.
+	RuleRedundantPipelines = "service.redundant-pipelines"
+	// RuleNoServices reports manifests with applications and no services, as
+	// no pipelines are generated for them, it is a warning by default.
+	RuleNoServices = "manifest.no-services"
+	// RuleWebhookProvider reports services with a webhook provider that is
+	// not the type of Git provider that hosts the source_url, as the webhook
+	// won't trigger the pipelines, it is a warning by default.
+	RuleWebhookProvider = "service.webhook-provider"
+	// RuleUndeclaredBinding reports services with a binding that is not
+	// referenced by the pipelines of their environment, or generated for the
+	// service, it is off by default, as services can use their own bindings.
+	RuleUndeclaredBinding = "service.undeclared-binding"
+	// RuleSecretNamespace reports services with a webhook secret that is not
+	// in the namespace of the environment or the pipelines, unless the service
+	// sets allow_cross_namespace_secret, it is a warning by default.
+	RuleSecretNamespace = "service.secret-namespace"
+)
+
+var defaultSeverities = map[string]Severity{
+	RuleMissingWebhook:             SeverityOff,
+	RuleDuplicateSource:            SeverityError,
+	RuleGitType:                    SeverityError,
+	RuleUncheckedGitType:           SeverityWarn,
+	RuleNameTooLong:                SeverityError,
+	RuleNameNearLimit:              SeverityWarn,
+	RuleEnvironmentWithoutApps:     SeverityWarn,
+	RuleConfigRepoWithoutPipelines: SeverityWarn,
+	RuleApplicationWithoutSource:   SeverityOff,
+	RuleTriggerNameTruncated:       SeverityWarn,
+	RuleDuplicateWebhookSecret:     SeverityError,
+	RuleWebhooksNotSupported:       SeverityWarn,
+	RuleUnsupportedWebhook:         SeverityError,
+	RuleBindingCollision:           SeverityOff,
+	RuleRedundantPipelines:         SeverityWarn,
+	RuleNoServices:                 SeverityWarn,
+	RuleWebhookProvider:            SeverityWarn,
+	RuleUndeclaredBinding:          SeverityOff,
+	RuleSecretNamespace:            SeverityWarn,
+}
+
+// ValidationPolicy maps rule identifiers e.g. RuleMissingWebhook to the
+// severity they are reported with, the rules that are not in the policy are
+// reported with their default severity.
+type ValidationPolicy map[string]Severity
+
+// WithValidationPolicy reports the problems found by the rules in the policy
+// with the configured severities, unknown rules and severities are reported as
+// errors.
+func WithValidationPolicy(policy ValidationPolicy) ValidateOption {
+	return func(vv *validateVisitor) {
+		vv.policy = policy
+	}
+}
+
+func (vv *validateVisitor) severity(rule string) Severity {
+	if s, ok := vv.policy[rule]; ok {
+		return s
+	}
+	return defaultSeverities[rule]
+}
+
+// ruleError returns the error if the rule is reported as an error, or records
+// it as a warning if the rule is reported as a warning.
+func (vv *validateVisitor) ruleError(rule string, err error) error {
+	switch vv.severity(rule) {
+	case SeverityError:
+		return err
+	case SeverityWarn:
+		if fe, ok := asFieldError(err); ok {
+			vv.warnings = append(vv.warnings, fmt.Sprintf("%s: %s", strings.Join(fe.Paths, ", "), fe.Message))
+		} else {
+			vv.warnings = append(vv.warnings, err.Error())
+		}
+	}
+	return nil
+}
+
+// warnRule records a warning for the rule, or an error if the rule is
+// reported as an error.
+func (vv *validateVisitor) warnRule(rule, path, format string, a ...interface{}) {
+	switch vv.severity(rule) {
+	case SeverityError:
+		vv.errs = append(vv.errs, &apis.FieldError{Message: fmt.Sprintf(format, a...), Paths: []string{path}})
+	case SeverityWarn:
+		vv.warn(path, format, a...)
+	}
+}
+
+// validatePolicy reports the rules and severities in the policy that are not
+// known.
+func (vv *validateVisitor) validatePolicy() []error {
+	rules := []string{}
+	for rule := range vv.policy {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	errs := []error{}
+	for _, rule := range rules {
+		if _, ok := defaultSeverities[rule]; !ok {
+			errs = append(errs, unknownRuleError(rule))
+			continue
+		}
+		switch vv.policy[rule] {
+		case SeverityError, SeverityWarn, SeverityOff:
+		default:
+			errs = append(errs, invalidSeverityError(rule, vv.policy[rule]))
+		}
+	}
+	return errs
+}
+
+func unknownRuleError(rule string) *apis.FieldError {
+	rules := []string{}
+	for r := range defaultSeverities {
+		rules = append(rules, r)
+	}
+	sort.Strings(rules)
+	return &apis.FieldError{
+		Message: fmt.Sprintf("unknown validation rule %q in the policy", rule),
+		Details: fmt.Sprintf("the rules are %s", strings.Join(rules, ", ")),
+	}
+}
+
+func invalidSeverityError(rule string, severity Severity) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid severity %q for validation rule %q in the policy", severity, rule),
+		Details: fmt.Sprintf("the severity must be one of %s, %s or %s", SeverityError, SeverityWarn, SeverityOff),
+	}
+}
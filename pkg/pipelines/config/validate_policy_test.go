@@ -0,0 +1,195 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mkmik/multierror"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"knative.dev/pkg/apis"
+)
+
+func TestValidateWithValidationPolicy(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/validation_policy.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	longName := "my-incredibly-long-name-for-a-test-service-that-fails"
+
+	err, warnings := m.ValidateWithWarnings()
+	want := multierror.Join([]error{
+		invalidNameError(longName, longServiceName, []string{"environments.development.apps.app-1.services." + longName}),
+		duplicateSourceError("https://github.com/myproject/service", []string{
+			"environments.development.apps.app-1.services.service-1",
+			"environments.development.apps.app-1.services.service-2"}),
+	})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+	wantWarnings := []string{`environments.staging: environment "staging" has no applications`}
+	if diff := cmp.Diff(wantWarnings, warnings); diff != "" {
+		t.Fatalf("warnings did not match:\n%s", diff)
+	}
+
+	err, warnings = m.ValidateWithWarnings(WithValidationPolicy(ValidationPolicy{
+		RuleMissingWebhook:         SeverityError,
+		RuleDuplicateSource:        SeverityWarn,
+		RuleNameTooLong:            SeverityOff,
+		RuleEnvironmentWithoutApps: SeverityError,
+	}))
+	want = multierror.Join([]error{
+		missingFieldsError([]string{"webhook"}, []string{"environments.development.apps.app-1.services.service-1"}),
+		missingFieldsError([]string{"webhook"}, []string{"environments.development.apps.app-1.services.service-2"}),
+		&apis.FieldError{Message: `environment "staging" has no applications`, Paths: []string{"environments.staging"}},
+	})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+	wantWarnings = []string{
+		"environments.development.apps.app-1.services.service-1, environments.development.apps.app-1.services.service-2: " +
+			"duplicate source detected, multiple services cannot share the same source repository: https://github.com/myproject/service",
+	}
+	if diff := cmp.Diff(wantWarnings, warnings); diff != "" {
+		t.Fatalf("warnings did not match:\n%s", diff)
+	}
+}
+
+func TestValidateWithInvalidValidationPolicy(t *testing.T) {
+	m := &Manifest{}
+
+	err := m.Validate(WithValidationPolicy(ValidationPolicy{
+		"service.unknown-rule": SeverityError,
+		RuleMissingWebhook:     "fatal",
+	}))
+	want := multierror.Join([]error{
+		invalidSeverityError(RuleMissingWebhook, "fatal"),
+		unknownRuleError("service.unknown-rule"),
+	})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateApplicationsWithoutSource(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/application_without_source.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	if err := m.Validate(); err != nil {
+		t.Fatalf("Validate() failed: %v", err)
+	}
+
+	err = m.Validate(WithValidationPolicy(ValidationPolicy{RuleApplicationWithoutSource: SeverityError}))
+	want := multierror.Join([]error{
+		missingPipelineSourceError("app-1", []string{"environments.development.apps.app-1"}),
+	})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateBindingOverrides(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/binding_overrides.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+
+	err, warnings := m.ValidateWithWarnings()
+	if err != nil {
+		t.Fatalf("Validate() failed: %v", err)
+	}
+	wantWarnings := []string{
+		`environments.development.apps.my-app-1.services.service-2.pipelines: service "service-2" has the same integration template and bindings as environment "development"`,
+	}
+	if diff := cmp.Diff(wantWarnings, warnings); diff != "" {
+		t.Fatalf("warnings did not match:\n%s", diff)
+	}
+
+	err = m.Validate(WithValidationPolicy(ValidationPolicy{RuleBindingCollision: SeverityError}))
+	want := multierror.Join([]error{
+		bindingCollisionError("dev-ci-binding", []string{
+			"environments.development.pipelines.integration.bindings",
+			"environments.development.apps.my-app-1.services.service-1.pipelines.integration.bindings"}),
+	})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateUndeclaredServiceBindings(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/undeclared_service_binding.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	if err := m.Validate(); err != nil {
+		t.Fatalf("Validate() failed: %v", err)
+	}
+
+	err = m.Validate(WithValidationPolicy(ValidationPolicy{RuleUndeclaredBinding: SeverityError}))
+	want := multierror.Join([]error{
+		unresolvedBindingError("service-ci-binding", "development",
+			[]string{"environments.development.apps.app-1.services.service-3.pipelines.integration.bindings"}),
+		unresolvedBindingError("github-push-binding", "staging",
+			[]string{"environments.staging.apps.app-1.services.service-1.pipelines.integration.bindings"}),
+	})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+
+	// The bindings declared in the config are checked instead.
+	m.Config = &Config{Pipelines: &PipelinesConfig{
+		Name:     "cicd",
+		Bindings: []string{"github-push-binding", "stage-ci-binding", "service-ci-binding", "development-app-1-service-2-binding"},
+	}}
+	if err := m.Validate(WithValidationPolicy(ValidationPolicy{RuleUndeclaredBinding: SeverityError})); err != nil {
+		t.Fatalf("Validate() failed: %v", err)
+	}
+}
+
+func TestValidateSecretNamespaces(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/webhook_secret_namespace.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+
+	err, warnings := m.ValidateWithWarnings()
+	if err != nil {
+		t.Fatalf("Validate() failed: %v", err)
+	}
+	wantWarnings := []string{
+		`environments.development.apps.my-app-1.services.service-3.webhook.secret.namespace: the webhook secret is in namespace "staging", which is not the namespace of the environment or the pipelines`,
+	}
+	if diff := cmp.Diff(wantWarnings, warnings); diff != "" {
+		t.Fatalf("warnings did not match:\n%s", diff)
+	}
+
+	err = m.Validate(WithValidationPolicy(ValidationPolicy{RuleSecretNamespace: SeverityError}))
+	want := multierror.Join([]error{
+		secretNamespaceError("staging", []string{"development", "cicd"},
+			[]string{"environments.development.apps.my-app-1.services.service-3.webhook.secret.namespace"}),
+	})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRuleErrorWarnings(t *testing.T) {
+	vv := newValidateVisitor()
+	vv.policy = ValidationPolicy{RuleDuplicateSource: SeverityWarn}
+
+	if err := vv.ruleError(RuleDuplicateSource, duplicateSourceError("github.com/org/repo", []string{"environments.dev"})); err != nil {
+		t.Fatalf("ruleError() got %v, want no error", err)
+	}
+	if err := vv.ruleError(RuleDuplicateSource, errors.New("failed to compare the sources")); err != nil {
+		t.Fatalf("ruleError() got %v, want no error", err)
+	}
+
+	want := []string{
+		"environments.dev: duplicate source detected, multiple services cannot share the same source repository: github.com/org/repo",
+		"failed to compare the sources",
+	}
+	if diff := cmp.Diff(want, vv.warnings); diff != "" {
+		t.Fatalf("warnings did not match:\n%s", diff)
+	}
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"knative.dev/pkg/apis"
+)
+
+// promotion records the environment that an environment promotes to, and the
+// path of the promotes_to field.
+type promotion struct {
+	to   string
+	path string
+}
+
+// validatePromotions checks that the environments that are promoted to exist,
+// and that the promotions form a single chain, or tree, without cycles, that
+// starts from one environment.
+func (vv *validateVisitor) validatePromotions() []error {
+	if len(vv.promotions) == 0 {
+		return nil
+	}
+	envs := map[string]bool{}
+	for _, name := range vv.environments {
+		envs[name] = true
+	}
+	sources := []string{}
+	for name := range vv.promotions {
+		sources = append(sources, name)
+	}
+	sort.Strings(sources)
+
+	errs := []error{}
+	graph := map[string][]string{}
+	promoted := map[string]bool{}
+	for _, name := range sources {
+		p := vv.promotions[name]
+		if !envs[p.to] {
+			errs = append(errs, unknownPromotionError(name, p.to, []string{p.path}))
+			continue
+		}
+		graph[name] = []string{p.to}
+		promoted[p.to] = true
+	}
+
+	seen := map[string]bool{}
+	cycles := 0
+	for _, name := range sources {
+		for _, cycle := range findCycles(name, graph) {
+			key := cycleKey(cycle)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			cycles++
+			paths := []string{}
+			for _, env := range cycle {
+				paths = append(paths, vv.promotions[env].path)
+			}
+			errs = append(errs, circularPromotionError(cycle, paths))
+		}
+	}
+	if cycles > 0 {
+		// The environments in a cycle have no root.
+		return errs
+	}
+
+	roots := []string{}
+	rootPaths := []string{}
+	for _, name := range sources {
+		if _, ok := graph[name]; ok && !promoted[name] {
+			roots = append(roots, name)
+			rootPaths = append(rootPaths, vv.promotions[name].path)
+		}
+	}
+	if len(roots) > 1 {
+		errs = append(errs, multiplePromotionRootsError(roots, rootPaths))
+	}
+	return errs
+}
+
+// findCycles returns the nodes that form each cycle reachable from start, in
+// the order that they are visited.
+func findCycles(start string, graph map[string][]string) [][]string {
+	cycles := [][]string{}
+	visited := map[string]bool{}
+	stack := []string{}
+	onStack := map[string]int{}
+	var visit func(string)
+	visit = func(node string) {
+		visited[node] = true
+		onStack[node] = len(stack)
+		stack = append(stack, node)
+		for _, next := range graph[node] {
+			if i, ok := onStack[next]; ok {
+				cycles = append(cycles, append([]string{}, stack[i:]...))
+				continue
+			}
+			if !visited[next] {
+				visit(next)
+			}
+		}
+		stack = stack[:len(stack)-1]
+		delete(onStack, node)
+	}
+	visit(start)
+	return cycles
+}
+
+// cycleKey identifies a cycle regardless of the environment it starts from.
+func cycleKey(cycle []string) string {
+	sorted := append([]string{}, cycle...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, " ")
+}
+
+func unknownPromotionError(env, to string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("environment %q promotes to %q, which does not exist", env, to),
+		Paths:   paths,
+	}
+}
+
+func circularPromotionError(envs, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("circular promotion detected: %s", strings.Join(append(envs, envs[0]), " -> ")),
+		Details: "an environment cannot be promoted back to itself",
+		Paths:   paths,
+	}
+}
+
+func multiplePromotionRootsError(envs, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("promotions start from more than one environment: %s", strings.Join(envs, ", ")),
+		Details: "the promotions must start from a single environment",
+		Paths:   paths,
+	}
+}
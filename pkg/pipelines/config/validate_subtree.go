@@ -0,0 +1,117 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"knative.dev/pkg/apis"
+)
+
+// ValidateEnvironment validates the named environment, and the applications
+// and services within it, without validating the rest of the manifest.
+//
+// The service source URLs are checked against the services in the other
+// environments, and the GitOps URL, but the checks that can only be made for
+// the whole manifest are skipped, these are the checks of the config, the
+// duplicate environment names, the clusters and namespaces shared with other
+// environments, and the cycles between config repositories.
+func (m *Manifest) ValidateEnvironment(envName string) error {
+	env := m.GetEnvironment(envName)
+	if env == nil {
+		return unknownEnvironmentError(envName, []string{yamlPath(PathForEnvironment(&Environment{Name: envName}))})
+	}
+	return m.validateSubtree(env, yamlPath(PathForEnvironment(env)))
+}
+
+// ValidateService validates the named service in the same way as
+// ValidateEnvironment, reporting only the errors for the service.
+//
+// The service name is checked against the other services in the environment.
+func (m *Manifest) ValidateService(envName, appName, svcName string) error {
+	env := m.GetEnvironment(envName)
+	if env == nil {
+		return unknownEnvironmentError(envName, []string{yamlPath(PathForEnvironment(&Environment{Name: envName}))})
+	}
+	app := m.GetApplication(envName, appName)
+	if app == nil {
+		return unknownApplicationError(appName, []string{yamlPath(PathForApplication(env, &Application{Name: appName}))})
+	}
+	path := yamlPath(PathForService(app, env, svcName))
+	for _, svc := range app.Services {
+		if svc.Name == svcName {
+			return m.validateSubtree(env, path)
+		}
+	}
+	return unknownServiceError(svcName, []string{path})
+}
+
+// validateSubtree validates the environment, and returns the errors with a
+// path within root.
+func (m *Manifest) validateSubtree(env *Environment, root string) error {
+	vv := newValidateVisitor()
+	// The config is validated for the name policy and the declared bindings,
+	// the errors are not part of any environment.
+	_ = vv.validateConfig(m)
+	subtree := Manifest{GitOpsURL: m.GitOpsURL, Config: m.Config, Environments: []*Environment{env}}
+	if err := vv.walk(&subtree, vv); err != nil {
+		return err
+	}
+
+	// The source URLs are also used by the services in other environments.
+	all := m.SourceRepositories()
+	for url := range vv.serviceURLs {
+		vv.serviceURLs[url] = all[url]
+	}
+	gitOpsURL := m.GitOpsURL
+	if validateGitURL(gitOpsURL, "gitops_url") != nil {
+		gitOpsURL = ""
+	}
+	vv.errs = append(vv.errs, vv.validateServiceURLs(gitOpsURL, !m.skipGitTypeValidation())...)
+	webhookPaths := []string{}
+	for _, path := range vv.webhookPaths {
+		if withinRoot(path, root) {
+			webhookPaths = append(webhookPaths, path)
+		}
+	}
+	if m.GitOpsURL == "" && len(webhookPaths) > 0 {
+		vv.errs = append(vv.errs, missingGitOpsURLError(webhookPaths))
+	}
+	vv.errs = append(vv.errs, vv.validateWebhookSecrets()...)
+
+	errs := []error{}
+	for _, err := range vv.errs {
+		if withinPath(err, root) {
+			errs = append(errs, err)
+		}
+	}
+	vv.errs = errs
+	return vv.err()
+}
+
+// withinPath returns true if any of the paths of the error are within root,
+// errors without paths, e.g. from identifying the Git driver for a URL, are
+// always within it.
+func withinPath(err error, root string) bool {
+	var fe *apis.FieldError
+	if !errors.As(err, &fe) || len(fe.Paths) == 0 {
+		return true
+	}
+	for _, path := range fe.Paths {
+		if withinRoot(path, root) {
+			return true
+		}
+	}
+	return false
+}
+
+func withinRoot(path, root string) bool {
+	return path == root || strings.HasPrefix(path, root+".")
+}
+
+func unknownServiceError(svc string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("unknown service %q", svc),
+		Paths:   paths,
+	}
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/mkmik/multierror"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestValidateEnvironment(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/validate_subtree.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+	sharedSource := duplicateSourceError("https://github.com/example/shared", []string{
+		"environments.development.apps.app-1.services.service-1",
+		"environments.staging.apps.app-1.services.service-1"})
+
+	tests := []struct {
+		env     string
+		wantErr error
+	}{
+		{"development", multierror.Join([]error{
+			sharedSource,
+			invalidNameError("service_2", DNS1035Error, []string{"environments.development.apps.app-1.services.service_2"}),
+			missingGitOpsURLError([]string{"environments.development.apps.app-2.services.service-3.webhook"}),
+		})},
+		{"staging", multierror.Join([]error{
+			sharedSource,
+			invalidNameError("service_4", DNS1035Error, []string{"environments.staging.apps.app-1.services.service_4"}),
+		})},
+		{"production", multierror.Join([]error{
+			invalidURLError("not-a-cluster-url", clusterURLDetails, []string{"environments.production.cluster"}),
+		})},
+		{"unknown", multierror.Join([]error{
+			unknownEnvironmentError("unknown", []string{"environments.unknown"}),
+		})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env, func(rt *testing.T) {
+			if err := matchMultiErrors(rt, m.ValidateEnvironment(tt.env), tt.wantErr); err != nil {
+				rt.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestValidateService(t *testing.T) {
+	m, err := ParseFile(ioutils.NewFilesystem(), "testdata/validate_subtree.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse file:%v", err)
+	}
+
+	tests := []struct {
+		desc    string
+		env     string
+		app     string
+		svc     string
+		wantErr error
+	}{
+		{"source URL shared with another environment", "staging", "app-1", "service-1", multierror.Join([]error{
+			duplicateSourceError("https://github.com/example/shared", []string{
+				"environments.development.apps.app-1.services.service-1",
+				"environments.staging.apps.app-1.services.service-1"}),
+		})},
+		{"invalid name", "development", "app-1", "service_2", multierror.Join([]error{
+			invalidNameError("service_2", DNS1035Error, []string{"environments.development.apps.app-1.services.service_2"}),
+		})},
+		{"webhook without a GitOps URL", "development", "app-2", "service-3", multierror.Join([]error{
+			missingGitOpsURLError([]string{"environments.development.apps.app-2.services.service-3.webhook"}),
+		})},
+		{"errors in the environment are not reported", "production", "app-1", "service-1", nil},
+		{"unknown environment", "unknown", "app-1", "service-1", multierror.Join([]error{
+			unknownEnvironmentError("unknown", []string{"environments.unknown"}),
+		})},
+		{"unknown application", "staging", "unknown", "service-1", multierror.Join([]error{
+			unknownApplicationError("unknown", []string{"environments.staging.apps.unknown"}),
+		})},
+		{"unknown service", "staging", "app-1", "unknown", multierror.Join([]error{
+			unknownServiceError("unknown", []string{"environments.staging.apps.app-1.services.unknown"}),
+		})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			if err := matchMultiErrors(rt, m.ValidateService(tt.env, tt.app, tt.svc), tt.wantErr); err != nil {
+				rt.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"sync"
+)
+
+// Validator validates manifests in the same way as Manifest.Validate, and
+// caches the results of validating each environment, so that validating a
+// changed manifest again e.g. in a watch loop, only validates the
+// environments that changed, and the checks across environments.
+//
+// The results are keyed by a hash of the environment and the config of the
+// manifest, and only the results for the last manifest that was validated are
+// kept.
+type Validator struct {
+	opts []ValidateOption
+
+	mu    sync.Mutex
+	cache *environmentCache
+}
+
+// NewValidator creates and returns a Validator that validates manifests with
+// the options.
+func NewValidator(opts ...ValidateOption) *Validator {
+	return &Validator{opts: opts, cache: newEnvironmentCache()}
+}
+
+// Validate validates the Manifest, returning the same errors as
+// Manifest.Validate with the options of the Validator.
+func (v *Validator) Validate(m *Manifest) error {
+	return v.validate(m).err()
+}
+
+// ValidateWithWarnings validates the Manifest, returning the same errors and
+// warnings as Manifest.ValidateWithWarnings with the options of the Validator.
+func (v *Validator) ValidateWithWarnings(m *Manifest) (errs error, warnings []string) {
+	vv := v.validate(m)
+	return vv.err(), vv.warnings
+}
+
+func (v *Validator) validate(m *Manifest) *validateVisitor {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	vv := newValidateVisitor()
+	for _, o := range v.opts {
+		o(vv)
+	}
+	vv.cache = v.cache
+	m.validateWith(vv)
+	v.cache.evict()
+	return vv
+}
+
+// cacheKey is the hash of an environment and the config it was validated
+// with, the zero key is never cached.
+type cacheKey [sha256.Size]byte
+
+// environmentCache maps the keys of the environments to the forked visitors
+// that validated them.
+type environmentCache struct {
+	entries map[cacheKey]*validateVisitor
+	// used are the entries of the last validation, the other entries are
+	// evicted after it.
+	used map[cacheKey]*validateVisitor
+}
+
+func newEnvironmentCache() *environmentCache {
+	return &environmentCache{
+		entries: map[cacheKey]*validateVisitor{},
+		used:    map[cacheKey]*validateVisitor{},
+	}
+}
+
+// key returns the key of the environment, the environments are validated with
+// the config e.g. the name policy, so changes to it invalidate them.
+func (c *environmentCache) key(config *Config, env *Environment) cacheKey {
+	b, err := json.Marshal(struct {
+		Config      *Config      `json:"config"`
+		Environment *Environment `json:"environment"`
+	}{config, env})
+	if err != nil {
+		return cacheKey{}
+	}
+	return sha256.Sum256(b)
+}
+
+func (c *environmentCache) get(key cacheKey) *validateVisitor {
+	if key == (cacheKey{}) {
+		return nil
+	}
+	return c.entries[key]
+}
+
+func (c *environmentCache) put(key cacheKey, f *validateVisitor) {
+	if key != (cacheKey{}) {
+		c.used[key] = f
+	}
+}
+
+// evict removes the entries that were not used by the last validation.
+func (c *environmentCache) evict() {
+	c.entries, c.used = c.used, map[cacheKey]*validateVisitor{}
+}
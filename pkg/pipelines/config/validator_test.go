@@ -0,0 +1,114 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestValidator(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := [][]ValidateOption{
+		nil,
+		{WithGlobalServiceUniqueness()},
+		{WithServiceNameTruncation()},
+		{WithConcurrency(4)},
+	}
+
+	for _, f := range files {
+		for i, opts := range options {
+			t.Run(fmt.Sprintf("%s/%d", filepath.Base(f), i), func(rt *testing.T) {
+				m, err := ParseFile(ioutils.NewFilesystem(), f)
+				if err != nil {
+					rt.Skipf("failed to parse file: %v", err)
+				}
+				v := NewValidator(opts...)
+				// The second validation uses the cached results.
+				assertSameAsValidator(rt, v, m, opts)
+				assertSameAsValidator(rt, v, m, opts)
+			})
+		}
+	}
+}
+
+func TestValidatorWithChangedEnvironment(t *testing.T) {
+	m := &Manifest{GitOpsURL: "https://github.com/org/gitops.git"}
+	for e := 0; e < 5; e++ {
+		env := &Environment{Name: fmt.Sprintf("env-%d", e)}
+		for a := 0; a < 3; a++ {
+			env.Apps = append(env.Apps, &Application{
+				Name: fmt.Sprintf("app-%d", a),
+				Services: []*Service{
+					{Name: fmt.Sprintf("service-%d", a), SourceURL: fmt.Sprintf("https://github.com/org/service-%d-%d.git", e, a)},
+				},
+			})
+		}
+		m.Environments = append(m.Environments, env)
+	}
+	v := NewValidator()
+	assertSameAsValidator(t, v, m, nil)
+	cached := v.cache.entries
+
+	// The service is changed in place, and duplicates a source URL in another
+	// environment.
+	m.Environments[2].Apps[1].Services[0].Name = "service_1"
+	m.Environments[2].Apps[1].Services[0].SourceURL = "https://github.com/org/service-0-0.git"
+	assertSameAsValidator(t, v, m, nil)
+
+	reused := 0
+	for key, f := range v.cache.entries {
+		if cached[key] == f {
+			reused++
+		}
+	}
+	if reused != 4 {
+		t.Fatalf("got %d cached environments, want 4", reused)
+	}
+	if len(v.cache.entries) != 5 {
+		t.Fatalf("got %d cache entries, want 5", len(v.cache.entries))
+	}
+
+	// The change is reverted.
+	m.Environments[2].Apps[1].Services[0].Name = "service-1"
+	m.Environments[2].Apps[1].Services[0].SourceURL = "https://github.com/org/service-2-1.git"
+	assertSameAsValidator(t, v, m, nil)
+}
+
+func TestValidatorWithChangedConfig(t *testing.T) {
+	m := &Manifest{
+		Environments: []*Environment{
+			{Name: "1-development", Apps: []*Application{{Name: "app-1", Services: []*Service{{Name: "service-1"}}}}},
+		},
+	}
+	v := NewValidator()
+	assertSameAsValidator(t, v, m, nil)
+	if v.Validate(m) == nil {
+		t.Fatal("expected the environment name to be invalid")
+	}
+
+	m.Config = &Config{NamePolicy: NamePolicyDNS1123}
+	assertSameAsValidator(t, v, m, nil)
+	if err := v.Validate(m); err != nil {
+		t.Fatalf("environment was not validated with the changed config: %s", err)
+	}
+}
+
+// assertSameAsValidator fails if validating the manifest with the Validator
+// returns different errors or warnings to validating it with the options.
+func assertSameAsValidator(t *testing.T, v *Validator, m *Manifest, opts []ValidateOption) {
+	t.Helper()
+	wantErr, wantWarnings := m.ValidateWithWarnings(opts...)
+	gotErr, gotWarnings := v.ValidateWithWarnings(m)
+	if diff := cmp.Diff(fmt.Sprint(wantErr), fmt.Sprint(gotErr)); diff != "" {
+		t.Fatalf("errors did not match:\n%s", diff)
+	}
+	if diff := cmp.Diff(wantWarnings, gotWarnings); diff != "" {
+		t.Fatalf("warnings did not match:\n%s", diff)
+	}
+}
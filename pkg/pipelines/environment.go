@@ -37,7 +37,7 @@ func AddEnv(o *EnvParameters, appFs afero.Fs) error {
 	}
 	m.Environments = append(m.Environments, newEnv)
 	files[pipelinesFile] = m
-	built, err := buildResources(appFs, m)
+	built, err := buildResources(appFs, m, config.Names{})
 	if err != nil {
 		return fmt.Errorf("failed to build resources: %v", err)
 	}
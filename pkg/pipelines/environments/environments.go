@@ -28,7 +28,7 @@ const (
 )
 
 const (
-	kustomization  = "kustomization.yaml"
+	kustomization  = config.KustomizationFile
 	vcsSourceLabel = "app.openshift.io/vcs-source"
 )
 
@@ -89,7 +89,7 @@ func (b *envBuilder) Service(app *config.Application, env *config.Environment, s
 		return nil
 	}
 	envBasePath := filepath.Join(config.PathForEnvironment(env), "env", "base")
-	envBindingPath := filepath.Join(envBasePath, fmt.Sprintf("%s-rolebinding.yaml", env.Name))
+	envBindingPath := filepath.Join(envBasePath, config.EnvironmentRoleBindingFile(env))
 	if _, ok := b.files[envBindingPath]; !ok {
 		b.files[envBindingPath] = createRoleBinding(env, b.pipelinesConfig.Name, b.saName)
 	}
@@ -104,7 +104,7 @@ func (b *envBuilder) Environment(env *config.Environment) error {
 	if err != nil {
 		return fmt.Errorf("failed to list initial files for %s: %s", basePath, err)
 	}
-	envBindingPath := filepath.Join(basePath, fmt.Sprintf("%s-rolebinding.yaml", env.Name))
+	envBindingPath := filepath.Join(basePath, config.EnvironmentRoleBindingFile(env))
 	if _, ok := b.files[envBindingPath]; ok {
 		envFiles[envBindingPath] = b.files[envBindingPath]
 	}
@@ -133,8 +133,8 @@ func (b *envBuilder) Environment(env *config.Environment) error {
 
 func filesForEnvironment(basePath string, env *config.Environment, gitOpsRepoURL string) res.Resources {
 	envFiles := res.Resources{}
-	filename := filepath.Join(basePath, fmt.Sprintf("%s-environment.yaml", env.Name))
-	envFiles[filename] = namespaces.Create(env.Name, gitOpsRepoURL)
+	filename := filepath.Join(basePath, config.EnvironmentNamespaceFile(env))
+	envFiles[filename] = namespaces.Create(config.EnvironmentNamespace(env), gitOpsRepoURL)
 	return envFiles
 }
 
@@ -175,7 +175,7 @@ func filesForApplication(env *config.Environment, fullname, appPath string, app
 
 func createRoleBinding(env *config.Environment, cicdNS, saName string) *v1.RoleBinding {
 	sa := roles.CreateServiceAccount(meta.NamespacedName(cicdNS, saName))
-	return roles.CreateRoleBinding(meta.NamespacedName(env.Name, fmt.Sprintf("%s-rolebinding", env.Name)), sa, "ClusterRole", "edit")
+	return roles.CreateRoleBinding(meta.NamespacedName(config.EnvironmentNamespace(env), fmt.Sprintf("%s-rolebinding", env.Name)), sa, "ClusterRole", "edit")
 }
 
 func filesForService(svcPath string) (res.Resources, error) {
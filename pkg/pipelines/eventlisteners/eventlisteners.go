@@ -31,7 +31,7 @@ func Generate(repo scm.Repository, ns, saName, secretName string) triggersv1.Eve
 		Spec: triggersv1.EventListenerSpec{
 			ServiceAccountName: saName,
 			Triggers: []triggersv1.EventListenerTrigger{
-				repo.CreatePushTrigger("ci-dryrun-from-push", secretName, ns, "ci-dryrun-from-push-template", []string{"github-push-binding"}),
+				repo.CreatePushTrigger("ci-dryrun-from-push", secretName, ns, WebhookSecretKey, "ci-dryrun-from-push-template", []string{"github-push-binding"}),
 			},
 		},
 	}
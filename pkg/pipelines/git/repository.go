@@ -9,6 +9,8 @@ import (
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/jenkins-x/go-scm/scm/transport"
+	kamscm "github.com/redhat-developer/kam/pkg/pipelines/scm"
 )
 
 // Repository represent a Git repository ofa specific Git repository URL
@@ -25,8 +27,7 @@ func NewRepository(rawURL, token string) (*Repository, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse repository URL %q: %w", rawURL, err)
 	}
-	parsed.User = url.UserPassword("", token)
-	client, err := factory.FromRepoURL(parsed.String())
+	client, err := newClient(parsed, token)
 	if err != nil {
 		return nil, err
 	}
@@ -38,6 +39,29 @@ func NewRepository(rawURL, token string) (*Repository, error) {
 	return &Repository{name: repoName, Client: client}, nil
 }
 
+// newClient creates a client for the driver of the repository host, the
+// drivers registered with kam are used as well as the go-scm defaults.
+func newClient(repoURL *url.URL, token string) (*scm.Client, error) {
+	driver, err := kamscm.GetDriverName(repoURL.String())
+	if err != nil {
+		return nil, err
+	}
+	serverURL := url.URL{Scheme: repoURL.Scheme, Host: repoURL.Host, Path: "/"}
+	if driver != "gitea" {
+		return factory.NewClient(driver, serverURL.String(), token)
+	}
+	// The Gitea API client keeps the HTTP client that the driver is created
+	// with, so the token is added to its transport, rather than replacing it.
+	client, err := factory.NewClient(driver, serverURL.String(), "")
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		client.Client.Transport = &transport.Authorization{Scheme: "token", Credentials: token}
+	}
+	return client, nil
+}
+
 // ListWebhooks returns a list of webhook IDs of the given listener in this repository
 func (r *Repository) ListWebhooks(listenerURL string) ([]string, error) {
 	hooks, _, err := r.Client.Repositories.ListHooks(context.Background(), r.name, scm.ListOptions{})
@@ -85,25 +109,16 @@ func (r *Repository) CreateWebhook(listenerURL, secret string) (string, error) {
 	return created.ID, err
 }
 
-// TODO: this likely won't work for GitLab projects because it assumes that the
-// path is always composed of two elements.
 // GetRepoName takes a URL of the form https://github.com/my-org/my-repo.git and
 // attempts to determine the name of the repo from this, i.e. "my-org/my-repo".
+//
+// GitLab projects can be nested in subgroups, so the name can have more than
+// two elements e.g. https://gitlab.com/my-org/team/my-repo.git gives
+// "my-org/team/my-repo".
 func GetRepoName(u *url.URL) (string, error) {
-	var components []string
-	for _, s := range strings.Split(u.Path, "/") {
-		if s != "" {
-			components = append(components, s)
-		}
-	}
-	if len(components) != 2 {
+	_, owner, repo, err := kamscm.ParseRepo(u.String())
+	if err != nil || strings.Contains(owner, ".") || strings.Contains(repo, ".") {
 		return "", errors.New("failed to get Git repo: " + u.Path)
 	}
-	components[1] = strings.TrimSuffix(components[1], ".git")
-	for _, s := range components {
-		if strings.Contains(s, ".") {
-			return "", errors.New("failed to get Git repo: " + u.Path)
-		}
-	}
-	return components[0] + "/" + components[1], nil
+	return owner + "/" + repo, nil
 }
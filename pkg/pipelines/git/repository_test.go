@@ -1,6 +1,7 @@
 package git
 
 import (
+	"net/url"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -103,6 +104,31 @@ func TestListWebHooks(t *testing.T) {
 	}
 }
 
+func TestListWebHooksWithGitea(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://gitea.com").
+		Get("/api/v1/repos/foo/bar/hooks").
+		MatchHeader("Authorization", "token mytoken").
+		Reply(200).
+		Type("application/json").
+		File("testdata/gitea_hooks.json")
+
+	repo, err := NewRepository("https://gitea.com/foo/bar.git", "mytoken")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := repo.ListWebhooks("http://example.com/webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(ids, []string{"1"}); diff != "" {
+		t.Errorf("driver errMsg mismatch got\n%s", diff)
+	}
+}
+
 func TestDeleteWebHooks(t *testing.T) {
 	defer gock.Off()
 
@@ -151,3 +177,38 @@ func TestCreateWebHook(t *testing.T) {
 		t.Errorf("failed to create webhook, got %q, want %q", created, "1")
 	}
 }
+
+func TestGetRepoName(t *testing.T) {
+	nameTests := []struct {
+		repoURL  string
+		wantName string
+		wantErr  string
+	}{
+		{"https://github.com/my-org/my-repo.git", "my-org/my-repo", ""},
+		{"https://gitlab.com/my-org/team/my-repo.git", "my-org/team/my-repo", ""},
+		{"https://gitlab.com/my-org/team/subteam/my-repo", "my-org/team/subteam/my-repo", ""},
+		{"https://github.com/my-repo.git", "", "failed to get Git repo: /my-repo.git"},
+		{"https://github.com/my.org/my-repo.git", "", "failed to get Git repo: /my.org/my-repo.git"},
+	}
+
+	for _, tt := range nameTests {
+		u, err := url.Parse(tt.repoURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name, err := GetRepoName(u)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("GetRepoName(%q) got error %v, want %q", tt.repoURL, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("GetRepoName(%q) failed: %s", tt.repoURL, err)
+			continue
+		}
+		if name != tt.wantName {
+			t.Errorf("GetRepoName(%q) got %q, want %q", tt.repoURL, name, tt.wantName)
+		}
+	}
+}
@@ -16,7 +16,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
-const registryURL = "image-registry.openshift-image-registry.svc:5000"
+const registryURL = config.InternalImageRegistry
 
 // ValidateImageRepo validates the input image repo.  It determines if it is
 // for internal registry and prepend internal registry hostname if necessary.
@@ -0,0 +1,39 @@
+package scm
+
+// DriverCapabilities are the features of a Git provider that kam can use.
+type DriverCapabilities struct {
+	// Webhooks is true if pushes can be delivered to an EventListener.
+	Webhooks bool
+	// PullRequestStatus is true if the pipeline status can be reported on
+	// commits and pull requests e.g. by the commit status tracker.
+	PullRequestStatus bool
+	// DeployKeys is true if repositories can have deploy keys.
+	DeployKeys bool
+	// Subgroups is true if repositories can be nested in groups e.g.
+	// org/group/repo, rather than only org/repo.
+	Subgroups bool
+}
+
+var allCapabilities = DriverCapabilities{Webhooks: true, PullRequestStatus: true, DeployKeys: true}
+
+// driverCapabilities are the capabilities of the known go-scm drivers, and the
+// drivers identified by kam.
+var driverCapabilities = map[string]DriverCapabilities{
+	githubType:     allCapabilities,
+	gitlabType:     {Webhooks: true, PullRequestStatus: true, DeployKeys: true, Subgroups: true},
+	giteaType:      allCapabilities,
+	"bitbucket":    allCapabilities,
+	"stash":        {Webhooks: true, PullRequestStatus: true, DeployKeys: true, Subgroups: true},
+	"gogs":         {Webhooks: true, DeployKeys: true},
+	codeCommitType: {},
+}
+
+// Capabilities returns the features supported by the driver e.g. "github", an
+// error is returned if the driver is not known.
+func Capabilities(driver string) (DriverCapabilities, error) {
+	c, ok := driverCapabilities[driver]
+	if !ok {
+		return DriverCapabilities{}, unsupportedGitTypeError(driver)
+	}
+	return c, nil
+}
@@ -0,0 +1,37 @@
+package scm
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCapabilities(t *testing.T) {
+	capabilityTests := []struct {
+		driver string
+		want   DriverCapabilities
+	}{
+		{"github", DriverCapabilities{Webhooks: true, PullRequestStatus: true, DeployKeys: true}},
+		{"gitlab", DriverCapabilities{Webhooks: true, PullRequestStatus: true, DeployKeys: true, Subgroups: true}},
+		{"gogs", DriverCapabilities{Webhooks: true, DeployKeys: true}},
+		{"codecommit", DriverCapabilities{}},
+	}
+
+	for _, tt := range capabilityTests {
+		got, err := Capabilities(tt.driver)
+		if err != nil {
+			t.Errorf("Capabilities(%q) failed: %s", tt.driver, err)
+			continue
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("Capabilities(%q) mismatch:\n%s", tt.driver, diff)
+		}
+	}
+}
+
+func TestCapabilitiesWithUnknownDriver(t *testing.T) {
+	_, err := Capabilities("unknown")
+	if msg := "unsupported Git repository type: unknown"; err == nil || err.Error() != msg {
+		t.Fatalf("Capabilities() got error %v, want %q", err, msg)
+	}
+}
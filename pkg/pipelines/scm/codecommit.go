@@ -0,0 +1,21 @@
+package scm
+
+// AWS CodeCommit repositories are identified so that they can be checked for
+// consistency with the GitOps repository, but there is no Repository
+// implementation, as CodeCommit can't deliver webhooks to an EventListener.
+const codeCommitType = "codecommit"
+
+// wellKnownDriverPatterns are the public hosts that the go-scm default
+// identifier doesn't know about, matched with path.Match.
+var wellKnownDriverPatterns = []driverMapping{
+	{hostPattern: "git-codecommit.*.amazonaws.com", driver: codeCommitType},
+	{hostPattern: "git-codecommit.*.amazonaws.com.cn", driver: codeCommitType},
+}
+
+// SupportsWebhooks returns true if repositories hosted by the driver can
+// deliver webhooks, so that the hooks can be skipped for the drivers that
+// can't, drivers with unknown capabilities are assumed to support them.
+func SupportsWebhooks(driver string) bool {
+	c, err := Capabilities(driver)
+	return err != nil || c.Webhooks
+}
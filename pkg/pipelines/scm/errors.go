@@ -0,0 +1,40 @@
+package scm
+
+import "fmt"
+
+// UnknownDriverError is returned when the go-scm driver for a repository URL
+// can't be identified from its host.
+type UnknownDriverError struct {
+	Host string
+	URL  string
+}
+
+func (e *UnknownDriverError) Error() string {
+	return fmt.Sprintf("unable to identify driver from hostname: %s", e.Host)
+}
+
+// InvalidURLError is returned when a repository URL can't be parsed.
+type InvalidURLError struct {
+	URL string
+	Err error
+}
+
+func (e *InvalidURLError) Error() string {
+	return fmt.Sprintf("invalid repository URL %s: %s", e.URL, e.Err)
+}
+
+// Unwrap returns the error from parsing the URL.
+func (e *InvalidURLError) Unwrap() error {
+	return e.Err
+}
+
+// WebhooksNotSupportedError is returned when a webhook is required for a
+// repository whose driver can't deliver webhooks.
+type WebhooksNotSupportedError struct {
+	Driver string
+	URL    string
+}
+
+func (e *WebhooksNotSupportedError) Error() string {
+	return fmt.Sprintf("webhooks are not supported for %s repositories: %s", e.Driver, e.URL)
+}
@@ -0,0 +1,77 @@
+package scm
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+)
+
+const (
+	giteaPushEventFilters = "(header.match('X-Gitea-Event', 'push') && body.repository.full_name == '%s')"
+	giteaType             = "gitea"
+)
+
+type giteaSpec struct {
+	pushBinding string
+}
+
+func init() {
+	gits[giteaType] = newGitea
+}
+
+func newGitea(rawURL string) (Repository, error) {
+	path, err := processRawURL(rawURL, proccessGiteaPath)
+	if err != nil {
+		return nil, err
+	}
+	return &repository{url: rawURL, path: path, spec: &giteaSpec{pushBinding: "gitea-push-binding"}}, nil
+}
+
+func proccessGiteaPath(parsedURL *url.URL) (string, error) {
+	components, err := splitRepositoryPath(parsedURL)
+	if err != nil {
+		return "", err
+	}
+
+	if len(components) != 2 {
+		return "", invalidRepoPathError(giteaType, parsedURL.Path)
+	}
+	path := strings.Join(components, "/")
+	return path, nil
+}
+
+func (r *giteaSpec) pushBindingName() string {
+	return r.pushBinding
+}
+
+func (r *giteaSpec) pushBindingParams() []triggersv1.Param {
+	return []triggersv1.Param{
+		createBindingParam("gitrepositoryurl", "$(body.repository.clone_url)"),
+		createBindingParam("fullname", "$(body.repository.full_name)"),
+		createBindingParam(triggers.GitRef, "$(body.ref)"),
+		createBindingParam(triggers.GitCommitID, "$(body.after)"),
+		createBindingParam(triggers.GitCommitDate, "$(body.commits[-1:].timestamp)"),
+		createBindingParam(triggers.GitCommitMessage, "$(body.commits[-1:].message)"),
+		createBindingParam(triggers.GitCommitAuthor, "$(body.commits[-1:].author.name)"),
+	}
+}
+
+func (r *giteaSpec) pushEventFilters() string {
+	return giteaPushEventFilters
+}
+
+// Gitea signs webhook payloads with the same X-Hub-Signature header as GitHub,
+// so they are verified with the GitHub interceptor.
+func (r *giteaSpec) eventInterceptor(secretNamespace, secretName, secretKey string) *triggersv1.EventInterceptor {
+	return &triggersv1.EventInterceptor{
+		GitHub: &triggersv1.GitHubInterceptor{
+			SecretRef: &triggersv1.SecretRef{
+				SecretName: secretName,
+				SecretKey:  secretKey,
+				Namespace:  secretNamespace,
+			},
+		},
+	}
+}
@@ -0,0 +1,139 @@
+package scm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreatePushBindingForGitea(t *testing.T) {
+	repo, err := NewRepository("https://gitea.com/org/test")
+	assertNoError(t, err)
+	want := triggersv1.TriggerBinding{
+		TypeMeta: triggers.TriggerBindingTypeMeta,
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "gitea-push-binding",
+			Namespace: "testns",
+		},
+		Spec: triggersv1.TriggerBindingSpec{
+			Params: []triggersv1.Param{
+				{
+					Name:  "gitrepositoryurl",
+					Value: "$(body.repository.clone_url)",
+				},
+				{
+					Name:  "fullname",
+					Value: "$(body.repository.full_name)",
+				},
+				{
+					Name:  triggers.GitRef,
+					Value: "$(body.ref)",
+				},
+				{
+					Name:  triggers.GitCommitID,
+					Value: "$(body.after)",
+				},
+				{
+					Name:  triggers.GitCommitDate,
+					Value: "$(body.commits[-1:].timestamp)",
+				},
+				{
+					Name:  triggers.GitCommitMessage,
+					Value: "$(body.commits[-1:].message)",
+				},
+				{
+					Name:  triggers.GitCommitAuthor,
+					Value: "$(body.commits[-1:].author.name)",
+				},
+			},
+		},
+	}
+	got, name := repo.CreatePushBinding("testns")
+	if name != "gitea-push-binding" {
+		t.Fatalf("CreatePushBinding() returned a wrong binding: want %v got %v", "gitea-push-binding", name)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePushBinding() failed:\n%s", diff)
+	}
+}
+
+func TestCreatePushTriggerForGitea(t *testing.T) {
+	repo, err := NewRepository("https://gitea.com/org/test")
+	assertNoError(t, err)
+	want := triggersv1.EventListenerTrigger{
+		Name: "test",
+		Bindings: []*triggersv1.EventListenerBinding{
+			{Ref: "test-binding"},
+		},
+		Template: &triggersv1.EventListenerTemplate{Name: "test-template"},
+		Interceptors: []*triggersv1.EventInterceptor{
+			{
+				GitHub: &triggersv1.GitHubInterceptor{
+					SecretRef: &triggersv1.SecretRef{SecretKey: "webhook-secret-key", SecretName: "secret", Namespace: "ns"},
+				},
+			},
+			{
+				CEL: &triggersv1.CELInterceptor{
+					Filter:   fmt.Sprintf(giteaPushEventFilters, "org/test"),
+					Overlays: branchRefOverlay,
+				},
+			},
+		},
+	}
+	got := repo.CreatePushTrigger("test", "secret", "ns", "webhook-secret-key", "test-template", []string{"test-binding"})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePushTrigger() failed:\n%s", diff)
+	}
+}
+
+func TestNewGiteaRepository(t *testing.T) {
+	t.Cleanup(resetRegisteredDrivers)
+	assertNoError(t, RegisterDriver("gitea.internal", "gitea"))
+
+	tests := []struct {
+		url      string
+		repoPath string
+		errMsg   string
+	}{
+		{
+			"https://gitea.internal/",
+			"",
+			"invalid repository URL https://gitea.internal/: path is empty",
+		},
+		{
+			"https://gitea.internal/org/repo.git",
+			"org/repo",
+			"",
+		},
+		{
+			"https://gitea.com/org/repo",
+			"org/repo",
+			"",
+		},
+		{
+			"https://gitea.internal/org/team/repo.git",
+			"",
+			"invalid repository path for gitea: /org/team/repo.git",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("Test %d", i), func(rt *testing.T) {
+			repo, err := NewRepository(tt.url)
+			if err != nil {
+				if diff := cmp.Diff(tt.errMsg, err.Error()); diff != "" {
+					rt.Fatalf("repo path errMsg mismatch: \n%s", diff)
+				}
+			}
+			if repo != nil {
+				if diff := cmp.Diff(tt.repoPath, repo.(*repository).path); diff != "" {
+					rt.Fatalf("repo path mismatch: got\n%s", diff)
+				}
+			}
+		})
+	}
+}
@@ -62,12 +62,12 @@ func (r *githubSpec) pushEventFilters() string {
 	return githubPushEventFilters
 }
 
-func (r *githubSpec) eventInterceptor(secretNamespace, secretName string) *triggersv1.EventInterceptor {
+func (r *githubSpec) eventInterceptor(secretNamespace, secretName, secretKey string) *triggersv1.EventInterceptor {
 	return &triggersv1.EventInterceptor{
 		GitHub: &triggersv1.GitHubInterceptor{
 			SecretRef: &triggersv1.SecretRef{
 				SecretName: secretName,
-				SecretKey:  webhookSecretKey,
+				SecretKey:  secretKey,
 				Namespace:  secretNamespace,
 			},
 		},
@@ -84,7 +84,7 @@ func TestCreateCDTriggersForGithub(t *testing.T) {
 			},
 		},
 	}
-	got := repo.CreatePushTrigger("test", "secret", "ns", "test-template", []string{"test-binding"})
+	got := repo.CreatePushTrigger("test", "secret", "ns", "webhook-secret-key", "test-template", []string{"test-binding"})
 	if diff := cmp.Diff(want, got); diff != "" {
 		t.Fatalf("CreateCDTrigger() failed:\n%s", diff)
 	}
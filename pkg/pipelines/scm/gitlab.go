@@ -61,12 +61,12 @@ func (r *gitlabSpec) pushEventFilters() string {
 	return gitlabPushEventFilters
 }
 
-func (r *gitlabSpec) eventInterceptor(secretNamespace, secretName string) *triggersv1.EventInterceptor {
+func (r *gitlabSpec) eventInterceptor(secretNamespace, secretName, secretKey string) *triggersv1.EventInterceptor {
 	return &triggersv1.EventInterceptor{
 		GitLab: &triggersv1.GitLabInterceptor{
 			SecretRef: &triggersv1.SecretRef{
 				SecretName: secretName,
-				SecretKey:  webhookSecretKey,
+				SecretKey:  secretKey,
 				Namespace:  secretNamespace,
 			},
 		},
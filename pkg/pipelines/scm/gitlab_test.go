@@ -85,7 +85,7 @@ func TestCreateCDTriggersForGitLab(t *testing.T) {
 			},
 		},
 	}
-	got := repo.CreatePushTrigger("test", "secret", "ns", "test-template", []string{"test-binding"})
+	got := repo.CreatePushTrigger("test", "secret", "ns", "webhook-secret-key", "test-template", []string{"test-binding"})
 	if diff := cmp.Diff(want, got); diff != "" {
 		t.Fatalf("CreateCDTrigger() failed:\n%s", diff)
 	}
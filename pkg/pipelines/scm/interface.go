@@ -13,8 +13,9 @@ type Repository interface {
 	// Create a TriggerBinding for Push Request hooks
 	CreatePushBinding(namespace string) (triggersv1.TriggerBinding, string)
 
-	// Create an eventlistener trigger for Push event
-	CreatePushTrigger(name, secretName, secretNs, template string, bindings []string) triggersv1.EventListenerTrigger
+	// Create an eventlistener trigger for Push event, the webhook secret is
+	// read from the secretKey of the named secret
+	CreatePushTrigger(name, secretName, secretNs, secretKey, template string, bindings []string) triggersv1.EventListenerTrigger
 
 	// Git Repository URL
 	URL() string
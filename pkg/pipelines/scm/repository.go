@@ -6,10 +6,6 @@ import (
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 )
 
-const (
-	webhookSecretKey = "webhook-secret-key"
-)
-
 var (
 	gits = make(map[string]func(string) (Repository, error))
 )
@@ -23,7 +19,7 @@ type repository struct {
 type triggerSpec interface {
 	pushBindingParams() []triggersv1.Param
 	pushEventFilters() string
-	eventInterceptor(secretNamespace, secretName string) *triggersv1.EventInterceptor
+	eventInterceptor(secretNamespace, secretName, secretKey string) *triggersv1.EventInterceptor
 	pushBindingName() string
 }
 
@@ -55,10 +51,10 @@ func (r *repository) CreatePushBinding(ns string) (triggersv1.TriggerBinding, st
 }
 
 // CreatePushTrigger implements the Repository interface.
-func (r *repository) CreatePushTrigger(name, secretName, secretNS, template string, bindings []string) triggersv1.EventListenerTrigger {
+func (r *repository) CreatePushTrigger(name, secretName, secretNS, secretKey, template string, bindings []string) triggersv1.EventListenerTrigger {
 	return r.createTrigger(name, r.spec.pushEventFilters(),
 		template, bindings,
-		r.spec.eventInterceptor(secretNS, secretName))
+		r.spec.eventInterceptor(secretNS, secretName, secretKey))
 }
 
 // URL implements the Repository interface.
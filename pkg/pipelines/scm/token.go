@@ -0,0 +1,159 @@
+package scm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/factory"
+)
+
+const (
+	githubScopesHeader = "X-OAuth-Scopes"
+	gitlabTokenPath    = "api/v4/personal_access_tokens/self"
+)
+
+var (
+	// githubImpliedScopes are the GitHub scopes that are granted by a parent
+	// scope.
+	githubImpliedScopes = map[string][]string{
+		"repo":            {"repo:status", "repo_deployment", "public_repo", "repo:invite", "security_events"},
+		"admin:repo_hook": {"write:repo_hook", "read:repo_hook"},
+		"write:repo_hook": {"read:repo_hook"},
+	}
+
+	// gitlabScopes maps GitLab token scopes to the equivalent GitHub scopes,
+	// scopes are always required using the GitHub names.
+	gitlabScopes = map[string][]string{
+		"api":              {"repo", "admin:repo_hook"},
+		"write_repository": {"repo"},
+		"read_repository":  {"public_repo"},
+	}
+)
+
+// TokenInfo describes the access granted by a Git provider token.
+type TokenInfo struct {
+	// Driver is the go-scm driver for the provider e.g. "github".
+	Driver string
+	// Scopes are the scopes granted to the token, as reported by the provider.
+	Scopes []string
+
+	// granted are the equivalent GitHub scopes, including implied scopes.
+	granted map[string]bool
+}
+
+// VerifyToken authenticates to the Git provider that hosts the repository with
+// the token, and returns the scopes that the token has been granted.
+//
+// Only GitHub and GitLab are supported.
+func VerifyToken(ctx context.Context, rawURL, token string) (*TokenInfo, error) {
+	driver, err := GetDriverName(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, &InvalidURLError{URL: rawURL, Err: err}
+	}
+	serverURL := (&url.URL{Scheme: u.Scheme, Host: u.Host}).String()
+	client, err := factory.NewClient(driver, serverURL, token)
+	if err != nil {
+		return nil, err
+	}
+
+	switch driver {
+	case "github":
+		return githubTokenInfo(ctx, client)
+	case "gitlab":
+		return gitlabTokenInfo(ctx, client)
+	}
+	return nil, unsupportedGitTypeError(driver)
+}
+
+// RequireScopes returns an error listing the scopes that have not been granted
+// to the token.
+//
+// The scopes are GitHub scope names, GitLab scopes are mapped to the GitHub
+// scope that grants the equivalent access e.g. "api" grants
+// "admin:repo_hook".
+func RequireScopes(info *TokenInfo, scopes ...string) error {
+	missing := []string{}
+	for _, s := range scopes {
+		if !info.granted[s] {
+			missing = append(missing, s)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("the %s token is missing the required scope(s) %s, it has been granted %s",
+		info.Driver, strings.Join(missing, ", "), describeScopes(info.Scopes))
+}
+
+func githubTokenInfo(ctx context.Context, client *scm.Client) (*TokenInfo, error) {
+	res, err := client.Do(ctx, &scm.Request{Method: http.MethodGet, Path: "user"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify the github token: %w", err)
+	}
+	defer res.Body.Close()
+	if res.Status != http.StatusOK {
+		return nil, fmt.Errorf("failed to verify the github token: status %d", res.Status)
+	}
+	scopes := []string{}
+	for _, s := range strings.Split(res.Header.Get(githubScopesHeader), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+	return newTokenInfo("github", scopes, func(s string) []string {
+		return append([]string{s}, githubImpliedScopes[s]...)
+	}), nil
+}
+
+func gitlabTokenInfo(ctx context.Context, client *scm.Client) (*TokenInfo, error) {
+	res, err := client.Do(ctx, &scm.Request{Method: http.MethodGet, Path: gitlabTokenPath})
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify the gitlab token: %w", err)
+	}
+	defer res.Body.Close()
+	if res.Status != http.StatusOK {
+		return nil, fmt.Errorf("failed to verify the gitlab token: status %d", res.Status)
+	}
+	var token struct {
+		Scopes []string `json:"scopes"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("failed to decode the gitlab token: %w", err)
+	}
+	return newTokenInfo("gitlab", token.Scopes, func(s string) []string {
+		granted := []string{}
+		for _, g := range gitlabScopes[s] {
+			granted = append(granted, g)
+			granted = append(granted, githubImpliedScopes[g]...)
+		}
+		return granted
+	}), nil
+}
+
+func newTokenInfo(driver string, scopes []string, grants func(string) []string) *TokenInfo {
+	sort.Strings(scopes)
+	info := &TokenInfo{Driver: driver, Scopes: scopes, granted: map[string]bool{}}
+	for _, s := range scopes {
+		for _, g := range grants(s) {
+			info.granted[g] = true
+		}
+	}
+	return info
+}
+
+func describeScopes(scopes []string) string {
+	if len(scopes) == 0 {
+		return "no scopes"
+	}
+	return strings.Join(scopes, ", ")
+}
@@ -0,0 +1,84 @@
+package scm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h2non/gock"
+)
+
+func TestVerifyTokenWithGitHub(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/user").
+		MatchHeader("Authorization", "Bearer my-token").
+		Reply(200).
+		Type("application/json").
+		SetHeader("X-OAuth-Scopes", "repo, admin:repo_hook").
+		BodyString(`{"login": "test-user"}`)
+
+	info, err := VerifyToken(context.Background(), "https://github.com/org/repo.git", "my-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"admin:repo_hook", "repo"}, info.Scopes); diff != "" {
+		t.Fatalf("VerifyToken() scopes mismatch:\n%s", diff)
+	}
+	assertNoError(t, RequireScopes(info, "repo", "admin:repo_hook", "public_repo", "read:repo_hook"))
+}
+
+func TestVerifyTokenWithGitLab(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://gitlab.com").
+		Get("/api/v4/personal_access_tokens/self").
+		MatchHeader("Private-Token", "my-token").
+		Reply(200).
+		Type("application/json").
+		BodyString(`{"name": "kam", "scopes": ["read_repository", "write_repository"]}`)
+
+	info, err := VerifyToken(context.Background(), "https://gitlab.com/org/team/repo.git", "my-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"read_repository", "write_repository"}, info.Scopes); diff != "" {
+		t.Fatalf("VerifyToken() scopes mismatch:\n%s", diff)
+	}
+	assertNoError(t, RequireScopes(info, "repo"))
+
+	err = RequireScopes(info, "repo", "admin:repo_hook")
+	want := "the gitlab token is missing the required scope(s) admin:repo_hook, it has been granted read_repository, write_repository"
+	if err == nil || err.Error() != want {
+		t.Fatalf("RequireScopes() got error %v, want %q", err, want)
+	}
+}
+
+func TestVerifyTokenWithFailedAuthentication(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/user").
+		Reply(401).
+		Type("application/json").
+		BodyString(`{"message": "Bad credentials"}`)
+
+	_, err := VerifyToken(context.Background(), "https://github.com/org/repo.git", "bad-token")
+	want := "failed to verify the github token: status 401"
+	if err == nil || err.Error() != want {
+		t.Fatalf("VerifyToken() got error %v, want %q", err, want)
+	}
+}
+
+func TestRequireScopes(t *testing.T) {
+	info := newTokenInfo("github", []string{"public_repo"}, func(s string) []string {
+		return append([]string{s}, githubImpliedScopes[s]...)
+	})
+
+	err := RequireScopes(info, "repo", "admin:repo_hook")
+	want := "the github token is missing the required scope(s) repo, admin:repo_hook, it has been granted public_repo"
+	if err == nil || err.Error() != want {
+		t.Fatalf("RequireScopes() got error %v, want %q", err, want)
+	}
+}
@@ -108,6 +108,7 @@ func serviceResources(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions)
 			files = res.Merge(resources, files)
 			svc.Pipelines = &config.Pipelines{
 				Integration: &config.TemplateBinding{
+					Template: env.Pipelines.Integration.Template,
 					Bindings: append([]string{bindingName}, env.Pipelines.Integration.Bindings...),
 				},
 			}
@@ -118,13 +119,13 @@ func serviceResources(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions)
 	if err != nil {
 		return nil, err
 	}
-	err = m.Validate()
+	err = m.ValidateService(o.EnvName, o.AppName, svc.Name)
 	if err != nil {
 		return nil, err
 	}
 
 	files[filepath.Base(filepath.Join(o.PipelinesFolderPath, pipelinesFile))] = m
-	built, err := buildResources(appFs, m)
+	built, err := buildResources(appFs, m, config.Names{})
 	if err != nil {
 		return nil, err
 	}
@@ -181,10 +182,6 @@ func updateKustomization(appFs afero.Fs, base string) error {
 	return err
 }
 
-func makeSvcImageBindingName(envName, appName, svcName string) string {
-	return fmt.Sprintf("%s-%s-%s-binding", envName, appName, svcName)
-}
-
 func makeSvcImageBindingFilename(bindingName string) string {
 	return filepath.Join("06-bindings", bindingName+".yaml")
 }
@@ -194,7 +191,7 @@ func makeImageBindingPath(cfg *config.PipelinesConfig, imageRepoBindingFilename
 }
 
 func createSvcImageBinding(cfg *config.PipelinesConfig, env *config.Environment, appName, svcName, imageRepo string, isTLSVerify bool) (string, string, res.Resources) {
-	name := makeSvcImageBindingName(env.Name, appName, svcName)
+	name := config.ServiceImageBindingName(env.Name, appName, svcName)
 	filename := makeSvcImageBindingFilename(name)
 	resourceFilePath := makeImageBindingPath(cfg, filename)
 	return name, filename, res.Resources{resourceFilePath: triggers.CreateImageRepoBinding(cfg.Name, name, imageRepo, strconv.FormatBool(isTLSVerify))}
@@ -413,7 +413,7 @@ func TestServiceWithArgoCD(t *testing.T) {
 			},
 		},
 	}
-	argo, err := argocd.Build(argocd.ArgoCDNamespace, "http://github.com/org/test", m)
+	argo, err := argocd.Build(argocd.ArgoCDNamespace, "http://github.com/org/test", m, config.Names{})
 	assertNoError(t, err)
 	want = res.Merge(argo, want)
 	got, err := serviceResources(m, fakeFs, &AddServiceOptions{
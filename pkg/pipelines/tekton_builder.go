@@ -1,7 +1,6 @@
 package pipelines
 
 import (
-	"fmt"
 	"path/filepath"
 
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
@@ -15,9 +14,10 @@ type tektonBuilder struct {
 	files      res.Resources
 	gitOpsRepo string
 	triggers   []v1alpha1.EventListenerTrigger
+	names      config.Names
 }
 
-func buildEventListenerResources(gitOpsRepo string, m *config.Manifest) (res.Resources, error) {
+func buildEventListenerResources(gitOpsRepo string, m *config.Manifest, names config.Names) (res.Resources, error) {
 	if gitOpsRepo == "" {
 		return res.Resources{}, nil
 	}
@@ -26,7 +26,7 @@ func buildEventListenerResources(gitOpsRepo string, m *config.Manifest) (res.Res
 		return nil, nil
 	}
 	files := make(res.Resources)
-	tb := &tektonBuilder{files: files, gitOpsRepo: gitOpsRepo}
+	tb := &tektonBuilder{files: files, gitOpsRepo: gitOpsRepo, names: names}
 	triggers, err := createTriggersForCICD(tb.gitOpsRepo, cfg)
 	if err != nil {
 		return nil, err
@@ -42,7 +42,7 @@ func buildEventListenerResources(gitOpsRepo string, m *config.Manifest) (res.Res
 }
 
 func (tb *tektonBuilder) Service(app *config.Application, env *config.Environment, svc *config.Service) error {
-	if svc.SourceURL == "" {
+	if svc.SourceURL == "" || !supportsWebhooks(svc.SourceURL) {
 		return nil
 	}
 	repo, err := scm.NewRepository(svc.SourceURL)
@@ -50,7 +50,7 @@ func (tb *tektonBuilder) Service(app *config.Application, env *config.Environmen
 		return err
 	}
 	pipelines := getPipelines(env, svc, repo)
-	ciTrigger := repo.CreatePushTrigger(triggerName(svc.Name), svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, pipelines.Integration.Template, pipelines.Integration.Bindings)
+	ciTrigger := repo.CreatePushTrigger(tb.names.TriggerName(env.Name, app.Name, svc.Name), svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, svc.Webhook.Secret.SecretKey(), pipelines.Integration.Template, pipelines.Integration.Bindings)
 	tb.triggers = append(tb.triggers, ciTrigger)
 	return nil
 }
@@ -61,15 +61,26 @@ func getEventListenerPath(cicdPath string) string {
 
 func createTriggersForCICD(gitOpsRepo string, cfg *config.PipelinesConfig) ([]v1alpha1.EventListenerTrigger, error) {
 	triggers := []v1alpha1.EventListenerTrigger{}
+	if !supportsWebhooks(gitOpsRepo) {
+		return triggers, nil
+	}
 	repo, err := scm.NewRepository(gitOpsRepo)
 	if err != nil {
 		return []v1alpha1.EventListenerTrigger{}, err
 	}
-	ciTrigger := repo.CreatePushTrigger("ci-dryrun-from-push", eventlisteners.GitOpsWebhookSecret, cfg.Name, "ci-dryrun-from-push-template", []string{repo.PushBindingName()})
+	ciTrigger := repo.CreatePushTrigger("ci-dryrun-from-push", eventlisteners.GitOpsWebhookSecret, cfg.Name, eventlisteners.WebhookSecretKey, "ci-dryrun-from-push-template", []string{repo.PushBindingName()})
 	triggers = append(triggers, ciTrigger)
 	return triggers, nil
 }
 
+// supportsWebhooks returns false if the repository is hosted by a driver that
+// can't deliver webhooks, so there is no need for a trigger, unknown drivers
+// are reported when the repository is created.
+func supportsWebhooks(repoURL string) bool {
+	driver, err := scm.GetDriverName(repoURL)
+	return err != nil || scm.SupportsWebhooks(driver)
+}
+
 func getPipelines(env *config.Environment, svc *config.Service, r scm.Repository) *config.Pipelines {
 	pipelines := defaultPipelines(r)
 	if env.Pipelines != nil {
@@ -94,7 +105,3 @@ func clonePipelines(p *config.Pipelines) *config.Pipelines {
 		},
 	}
 }
-
-func triggerName(svc string) string {
-	return fmt.Sprintf("app-ci-build-from-push-%s", svc)
-}
@@ -29,7 +29,7 @@ func TestBuildEventListener(t *testing.T) {
 		GitOpsURL: "http://github.com/org/gitops.git",
 	}
 	cicdPath := filepath.Join("config", "test-cicd")
-	got, err := buildEventListenerResources(testRepoName, m)
+	got, err := buildEventListenerResources(testRepoName, m, config.Names{})
 	assertNoError(t, err)
 	want := res.Resources{
 		getEventListenerPath(cicdPath): eventlisteners.CreateELFromTriggers("test-cicd", saName, fakeTriggers(t, m, testRepoName)),
@@ -53,7 +53,7 @@ func TestBuildEventListenerWithServiceWithNoURL(t *testing.T) {
 	}
 	cicdPath := filepath.Join("config", "test-cicd")
 	gitOpsRepo := "http://github.com/org/gitops.git"
-	got, err := buildEventListenerResources(gitOpsRepo, m)
+	got, err := buildEventListenerResources(gitOpsRepo, m, config.Names{})
 	assertNoError(t, err)
 	want := res.Resources{
 		getEventListenerPath(cicdPath): eventlisteners.CreateELFromTriggers("test-cicd", saName, fakeTriggers(t, m, gitOpsRepo)),
@@ -63,6 +63,32 @@ func TestBuildEventListenerWithServiceWithNoURL(t *testing.T) {
 	}
 }
 
+func TestBuildEventListenerWithCodeCommit(t *testing.T) {
+	gitOpsRepo := "https://git-codecommit.us-east-1.amazonaws.com/v1/repos/gitops"
+	svc := testService()
+	svc.SourceURL = "https://git-codecommit.us-east-1.amazonaws.com/v1/repos/test"
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{
+				Name: "test-cicd",
+			},
+		},
+		Environments: []*config.Environment{
+			testEnv(svc, "dev"),
+		},
+		GitOpsURL: gitOpsRepo,
+	}
+	cicdPath := filepath.Join("config", "test-cicd")
+	got, err := buildEventListenerResources(gitOpsRepo, m, config.Names{})
+	assertNoError(t, err)
+	want := res.Resources{
+		getEventListenerPath(cicdPath): eventlisteners.CreateELFromTriggers("test-cicd", saName, nil),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("resources didn't match:%s\n", diff)
+	}
+}
+
 func TestBuildEventListenerWithNoGitOpsURL(t *testing.T) {
 	m := &config.Manifest{
 		Environments: []*config.Environment{
@@ -72,7 +98,7 @@ func TestBuildEventListenerWithNoGitOpsURL(t *testing.T) {
 			testEnv(testService(), "dev"),
 		},
 	}
-	got, err := buildEventListenerResources("", m)
+	got, err := buildEventListenerResources("", m, config.Names{})
 	assertNoError(t, err)
 
 	want := res.Resources{}
@@ -197,7 +223,7 @@ func fakeTriggers(t *testing.T, m *config.Manifest, gitOpsRepo string) []trigger
 		repo, err := scm.NewRepository(svc.SourceURL)
 		assertNoError(t, err)
 		pipelines := getPipelines(env, svc, repo)
-		devCITrigger := repo.CreatePushTrigger(fmt.Sprintf("app-ci-build-from-push-%s", svc.Name), svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, pipelines.Integration.Template, pipelines.Integration.Bindings)
+		devCITrigger := repo.CreatePushTrigger(fmt.Sprintf("app-ci-build-from-push-%s", svc.Name), svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, svc.Webhook.Secret.SecretKey(), pipelines.Integration.Template, pipelines.Integration.Bindings)
 		triggers = append(triggers, devCITrigger)
 	}
 
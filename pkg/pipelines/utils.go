@@ -5,9 +5,9 @@ import (
 	"fmt"
 	"net/url"
 	"os/exec"
-	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
+	kamscm "github.com/redhat-developer/kam/pkg/pipelines/scm"
 )
 
 const defaultRepoDescription = "Bootstrapped GitOps Repository"
@@ -29,9 +29,10 @@ func BootstrapRepository(o *BootstrapOptions, f clientFactory, e executor) error
 	if err != nil {
 		return fmt.Errorf("failed to parse GitOps repo URL %q: %w", o.GitOpsRepoURL, err)
 	}
-	parts := strings.Split(u.Path, "/")
-	org := parts[1]
-	repoName := strings.TrimSuffix(strings.Join(parts[2:], "/"), ".git")
+	_, org, repoName, err := kamscm.ParseRepo(o.GitOpsRepoURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse GitOps repo URL %q: %w", o.GitOpsRepoURL, err)
+	}
 	u.User = url.UserPassword("", o.GitHostAccessToken)
 
 	client, err := f(u.String())
@@ -9,6 +9,7 @@ import (
 	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
 	"github.com/redhat-developer/kam/pkg/pipelines/git"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
 )
 
@@ -21,6 +22,7 @@ type webhookInfo struct {
 	accessToken     string
 	serviceName     *QualifiedServiceName
 	isCICD          bool
+	secretKey       string
 }
 
 // QualifiedServiceName represents three part name of a service (Environment, Application, and Service)
@@ -53,6 +55,9 @@ func Create(accessToken, pipelinesFile string, serviceName *QualifiedServiceName
 // It returns the IDs of deleted webhooks.
 func Delete(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) ([]string, error) {
 	webhook, err := newWebhookInfo(accessToken, pipelinesFile, serviceName, isCICD)
+	if isWebhooksNotSupported(err) {
+		return []string{}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -68,6 +73,9 @@ func Delete(accessToken, pipelinesFile string, serviceName *QualifiedServiceName
 // List returns an array of webhook IDs for the target Git repository/listeners
 func List(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) ([]string, error) {
 	webhook, err := newWebhookInfo(accessToken, pipelinesFile, serviceName, isCICD)
+	if isWebhooksNotSupported(err) {
+		return []string{}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -75,6 +83,13 @@ func List(accessToken, pipelinesFile string, serviceName *QualifiedServiceName,
 	return webhook.list()
 }
 
+// isWebhooksNotSupported returns true if the repository can't have webhooks,
+// so there are none to list or delete.
+func isWebhooksNotSupported(err error) bool {
+	var unsupported *scm.WebhooksNotSupportedError
+	return errors.As(err, &unsupported)
+}
+
 func newWebhookInfo(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) (*webhookInfo, error) {
 	manifest, err := config.LoadManifest(ioutils.NewFilesystem(), pipelinesFile)
 	if err != nil {
@@ -85,6 +100,9 @@ func newWebhookInfo(accessToken, pipelinesFile string, serviceName *QualifiedSer
 	if gitRepoURL == "" {
 		return nil, errors.New("failed to find Git repository URL in manifest")
 	}
+	if driver, err := scm.GetDriverName(gitRepoURL); err == nil && !scm.SupportsWebhooks(driver) {
+		return nil, &scm.WebhooksNotSupportedError{Driver: driver, URL: gitRepoURL}
+	}
 
 	cfg := manifest.GetPipelinesConfig()
 	if cfg == nil {
@@ -111,7 +129,8 @@ func newWebhookInfo(accessToken, pipelinesFile string, serviceName *QualifiedSer
 	if err != nil {
 		return nil, err
 	}
-	return &webhookInfo{clusterResources, repository, gitRepoURL, cicdNamepace, listenerURL, accessToken, serviceName, isCICD}, nil
+	secretKey := getSecretKey(manifest, isCICD, serviceName)
+	return &webhookInfo{clusterResources, repository, gitRepoURL, cicdNamepace, listenerURL, accessToken, serviceName, isCICD, secretKey}, nil
 }
 
 func (w *webhookInfo) exists() (bool, error) {
@@ -132,7 +151,7 @@ func (w *webhookInfo) delete(ids []string) ([]string, error) {
 }
 
 func (w *webhookInfo) create() (string, error) {
-	secret, err := getWebhookSecret(w.clusterResource, w.cicdNamepace, w.isCICD, w.serviceName)
+	secret, err := getWebhookSecret(w.clusterResource, w.cicdNamepace, w.isCICD, w.serviceName, w.secretKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to get webhook secret: %v", err)
 	}
@@ -152,18 +171,37 @@ func getRepoURL(manifest *config.Manifest, isCICD bool, serviceName *QualifiedSe
 
 // Get service source repository URL.  Return "" if not found
 func getSourceRepoURL(manifest *config.Manifest, service *QualifiedServiceName) string {
+	if svc := getService(manifest, service); svc != nil {
+		return svc.SourceURL
+	}
+	return ""
+}
+
+// Get the key of the webhook secret in the secret data, services can configure
+// the key, otherwise the default key is used.
+func getSecretKey(manifest *config.Manifest, isCICD bool, service *QualifiedServiceName) string {
+	if !isCICD {
+		if svc := getService(manifest, service); svc != nil && svc.Webhook != nil && svc.Webhook.Secret != nil {
+			return svc.Webhook.Secret.SecretKey()
+		}
+	}
+	return eventlisteners.WebhookSecretKey
+}
+
+// Get the named service from the manifest.  Return nil if not found
+func getService(manifest *config.Manifest, service *QualifiedServiceName) *config.Service {
 	for _, env := range manifest.Environments {
 		if env.Name == service.EnvironmentName {
 			for _, app := range env.Apps {
 				for _, svc := range app.Services {
 					if svc.Name == service.ServiceName {
-						return svc.SourceURL
+						return svc
 					}
 				}
 			}
 		}
 	}
-	return ""
+	return nil
 }
 
 func getListenerURL(r *resources, cicdNamespace string) (string, error) {
@@ -185,7 +223,7 @@ func buildURL(host string, hasTLS bool) string {
 	return scheme + "://" + host
 }
 
-func getWebhookSecret(r *resources, namespace string, isCICD bool, service *QualifiedServiceName) (string, error) {
+func getWebhookSecret(r *resources, namespace string, isCICD bool, service *QualifiedServiceName, key string) (string, error) {
 	var secretName string
 	if isCICD {
 		secretName = eventlisteners.GitOpsWebhookSecret
@@ -194,5 +232,5 @@ func getWebhookSecret(r *resources, namespace string, isCICD bool, service *Qual
 		// also currently, service webhook secret are in CICI namespace
 		secretName = secrets.MakeServiceWebhookSecretName(service.EnvironmentName, service.ServiceName)
 	}
-	return r.getWebhookSecret(namespace, secretName, eventlisteners.WebhookSecretKey)
+	return r.getWebhookSecret(namespace, secretName, key)
 }
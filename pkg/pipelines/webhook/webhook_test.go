@@ -127,3 +127,48 @@ func TestGetGitRepoURL(t *testing.T) {
 		})
 	}
 }
+
+func TestGetSecretKey(t *testing.T) {
+	manifest := &config.Manifest{
+		Environments: []*config.Environment{
+			{
+				Name: "myenv",
+				Apps: []*config.Application{
+					{
+						Name: "myapp",
+						Services: []*config.Service{
+							{
+								Name: "myservice",
+								Webhook: &config.Webhook{
+									Secret: &config.Secret{Name: "webhook-secret", Namespace: "cicd", Key: "webhook-secret-2024"},
+								},
+							},
+							{
+								Name: "otherservice",
+								Webhook: &config.Webhook{
+									Secret: &config.Secret{Name: "webhook-secret", Namespace: "cicd"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	testcases := []struct {
+		isCICD      bool
+		serviceName *QualifiedServiceName
+		want        string
+	}{
+		{true, nil, "webhook-secret-key"},
+		{false, &QualifiedServiceName{EnvironmentName: "myenv", ServiceName: "myservice"}, "webhook-secret-2024"},
+		{false, &QualifiedServiceName{EnvironmentName: "myenv", ServiceName: "otherservice"}, "webhook-secret-key"},
+		{false, &QualifiedServiceName{EnvironmentName: "myenv", ServiceName: "unknown"}, "webhook-secret-key"},
+	}
+	for i, tt := range testcases {
+		if got := getSecretKey(manifest, tt.isCICD, tt.serviceName); got != tt.want {
+			t.Errorf("test %d: getSecretKey() got %q, want %q", i, got, tt.want)
+		}
+	}
+}